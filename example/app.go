@@ -18,7 +18,7 @@ type WebAdapter struct {
 
 func (we WebAdapter) Start() {
 	// Empty permissions indicates that no permissions are required
-	http.HandleFunc("/test", we.wrapFunc(we.test))
+	http.Handle("/test", logs.Middleware(we.logsger, &logs.MiddlewareOpts{Recovery: &logs.RecoveryOpts{}})(we.wrapFunc(we.test)))
 
 	http.ListenAndServe(":5000", nil)
 }
@@ -47,15 +47,12 @@ func checkParam(param string) error {
 	return errors.ErrorData(logutils.StatusInvalid, logutils.TypeArg, &logutils.FieldArgs{"param": param})
 }
 
-// wrapFunc provides a standard wrapper that performs request logsging
+// wrapFunc provides a standard wrapper that passes the request log created by logs.Middleware to the handler
 func (we WebAdapter) wrapFunc(handler handlerFunc) http.HandlerFunc {
 	// Receive request with tokens generated by auth service
 	return func(w http.ResponseWriter, req *http.Request) {
-		logsObj := we.logsger.NewRequestLog(req)
-
-		logsObj.RequestReceived()
+		logsObj := logs.FromContext(req.Context())
 		handler(logsObj, w, req)
-		logsObj.RequestComplete()
 	}
 }
 
@@ -0,0 +1,183 @@
+package logs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//defaultAsyncQueueSize is the default number of entries the async queue can hold
+const defaultAsyncQueueSize = 1024
+
+//fatalFlushTimeout is the maximum time spent writing queued entries when a Fatal log stops the service
+const fatalFlushTimeout = 5 * time.Second
+
+//AsyncOpts provides configuration options for writing logs asynchronously
+//	Logs are formatted when they are logged and written to the sinks by a background goroutine.
+//	Logger.Flush or Logger.Close should be called before the service exits so that queued logs are not lost.
+type AsyncOpts struct {
+	//QueueSize: The maximum number of entries waiting to be written (Default: 1024)
+	//			 Each entry is counted once for each sink it is written to
+	QueueSize int
+	//Overflow: The behavior when the queue is full (Default: OverflowBlock)
+	//			With OverflowDropDebugInfo, new Debug and Info entries are dropped, and Warn and Error entries replace
+	//			the oldest queued Debug or Info entry, or wait if there is none
+	Overflow OverflowPolicy
+}
+
+//asyncItem is a formatted entry waiting to be written to a sink
+type asyncItem struct {
+	sink  *sinkOutput
+	data  []byte
+	level logrus.Level
+}
+
+//asyncWriter writes formatted entries to sinks from a background goroutine
+type asyncWriter struct {
+	size     int
+	overflow OverflowPolicy
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	items   []asyncItem
+	writing bool
+	closed  bool
+	dropped uint64
+	done    chan struct{}
+}
+
+func newAsyncWriter(opts AsyncOpts) *asyncWriter {
+	size := opts.QueueSize
+	if size <= 0 {
+		size = defaultAsyncQueueSize
+	}
+
+	w := &asyncWriter{size: size, overflow: opts.Overflow, done: make(chan struct{})}
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w
+}
+
+//enqueue queues item to be written, applying the overflow policy if the queue is full
+//	Items are written synchronously once the writer is closed.
+func (w *asyncWriter) enqueue(item asyncItem) {
+	w.mu.Lock()
+	for !w.closed && len(w.items) >= w.size {
+		switch w.overflow {
+		case OverflowDropNewest:
+			w.dropped++
+			w.mu.Unlock()
+			return
+		case OverflowDropDebugInfo:
+			if item.level >= logrus.InfoLevel {
+				w.dropped++
+				w.mu.Unlock()
+				return
+			}
+			if w.dropDebugInfo() {
+				continue
+			}
+			w.cond.Wait()
+		default:
+			w.cond.Wait()
+		}
+	}
+
+	if w.closed {
+		w.mu.Unlock()
+		item.sink.writeBytes(item.data)
+		return
+	}
+
+	w.items = append(w.items, item)
+	w.cond.Broadcast()
+	w.mu.Unlock()
+}
+
+//dropDebugInfo drops the oldest queued Debug or Info entry
+//	Returns true if an entry was dropped. The lock must be held by the caller.
+func (w *asyncWriter) dropDebugInfo() bool {
+	for i, item := range w.items {
+		if item.level >= logrus.InfoLevel {
+			w.items = append(w.items[:i], w.items[i+1:]...)
+			w.dropped++
+			return true
+		}
+	}
+	return false
+}
+
+//run writes queued items until the writer is closed and the queue is empty
+func (w *asyncWriter) run() {
+	defer close(w.done)
+
+	for {
+		w.mu.Lock()
+		for len(w.items) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if len(w.items) == 0 {
+			w.mu.Unlock()
+			return
+		}
+
+		batch := w.items
+		w.items = make([]asyncItem, 0, len(batch))
+		w.writing = true
+		w.cond.Broadcast()
+		w.mu.Unlock()
+
+		for _, item := range batch {
+			item.sink.writeBytes(item.data)
+		}
+
+		w.mu.Lock()
+		w.writing = false
+		w.cond.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
+//flush waits until all queued items have been written or ctx is done
+func (w *asyncWriter) flush(ctx context.Context) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			//Wake the waiting flush so that it returns even if a sink does not complete its write
+			w.mu.Lock()
+			w.cond.Broadcast()
+			w.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.items) > 0 || w.writing {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		w.cond.Wait()
+	}
+	return nil
+}
+
+//close writes all queued items and stops the background goroutine
+func (w *asyncWriter) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+	<-w.done
+}
+
+//droppedEntries returns the number of entries dropped because the queue was full
+func (w *asyncWriter) droppedEntries() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
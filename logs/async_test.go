@@ -0,0 +1,146 @@
+package logs
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//gatedWriter records writes and blocks them until the gate is opened
+type gatedWriter struct {
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+
+	mu     sync.Mutex
+	writes []string
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *gatedWriter) written() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Join(w.writes, ",")
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	type item struct {
+		data  string
+		level logrus.Level
+	}
+
+	tests := []struct {
+		name        string
+		overflow    OverflowPolicy
+		items       []item
+		wantBlocked bool
+		wantWritten string
+		wantDropped uint64
+	}{
+		{name: "block", overflow: OverflowBlock,
+			items:       []item{{"a", logrus.InfoLevel}, {"b", logrus.InfoLevel}, {"c", logrus.DebugLevel}},
+			wantBlocked: true, wantWritten: "first,a,b,c"},
+		{name: "drop newest", overflow: OverflowDropNewest,
+			items:       []item{{"a", logrus.InfoLevel}, {"b", logrus.WarnLevel}, {"c", logrus.ErrorLevel}},
+			wantWritten: "first,a,b", wantDropped: 1},
+		{name: "drop debug info", overflow: OverflowDropDebugInfo,
+			items: []item{{"a", logrus.InfoLevel}, {"b", logrus.DebugLevel}, {"c", logrus.WarnLevel},
+				{"d", logrus.InfoLevel}, {"e", logrus.ErrorLevel}},
+			wantWritten: "first,c,e", wantDropped: 3},
+		{name: "drop debug info blocks warnings", overflow: OverflowDropDebugInfo,
+			items:       []item{{"a", logrus.WarnLevel}, {"b", logrus.ErrorLevel}, {"c", logrus.WarnLevel}},
+			wantBlocked: true, wantWritten: "first,a,b,c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := newGatedWriter()
+			sink := &sinkOutput{writer: writer}
+			w := newAsyncWriter(AsyncOpts{QueueSize: 2, Overflow: tt.overflow})
+
+			//The first item is taken by the background goroutine, which then waits on the gate with an empty queue
+			w.enqueue(asyncItem{sink: sink, data: []byte("first"), level: logrus.InfoLevel})
+			<-writer.started
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for _, item := range tt.items {
+					w.enqueue(asyncItem{sink: sink, data: []byte(item.data), level: item.level})
+				}
+			}()
+
+			blocked := false
+			select {
+			case <-done:
+			case <-time.After(50 * time.Millisecond):
+				blocked = true
+			}
+			if blocked != tt.wantBlocked {
+				t.Errorf("blocked = %v, want %v", blocked, tt.wantBlocked)
+			}
+
+			close(writer.gate)
+			<-done
+			w.close()
+
+			if got := writer.written(); got != tt.wantWritten {
+				t.Errorf("written = %s, want %s", got, tt.wantWritten)
+			}
+			if got := w.droppedEntries(); got != tt.wantDropped {
+				t.Errorf("droppedEntries() = %d, want %d", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestAsyncWriterFlush(t *testing.T) {
+	writer := newGatedWriter()
+	sink := &sinkOutput{writer: writer}
+	w := newAsyncWriter(AsyncOpts{})
+	defer w.close()
+
+	if err := w.flush(context.Background()); err != nil {
+		t.Errorf("flush() with an empty queue = %v", err)
+	}
+
+	w.enqueue(asyncItem{sink: sink, data: []byte("a"), level: logrus.InfoLevel})
+	w.enqueue(asyncItem{sink: sink, data: []byte("b"), level: logrus.InfoLevel})
+	<-writer.started
+
+	//The sink does not complete its write, so the flush must return when the context expires
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := w.flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("flush() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("flush() returned after %v", elapsed)
+	}
+
+	close(writer.gate)
+	if err := w.flush(context.Background()); err != nil {
+		t.Errorf("flush() = %v", err)
+	}
+	if got := writer.written(); got != "a,b" {
+		t.Errorf("written = %s, want a,b", got)
+	}
+}
@@ -0,0 +1,132 @@
+package logs
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	headerB3        = "b3"
+	headerB3TraceID = "X-B3-TraceId"
+	headerB3SpanID  = "X-B3-SpanId"
+	headerB3Sampled = "X-B3-Sampled"
+	headerB3Flags   = "X-B3-Flags"
+
+	b3SampledAccept = "1"
+	b3SampledDeny   = "0"
+	b3SampledDebug  = "d"
+)
+
+//B3Propagator reads and writes the trace context in the Zipkin B3 headers
+//	When SingleHeader is true, the single "b3" header is used. Otherwise the X-B3-* headers are used.
+//	Extract always accepts both forms, preferring the form selected by SingleHeader.
+type B3Propagator struct {
+	SingleHeader bool
+}
+
+//Extract reads the trace context from the B3 headers
+func (p B3Propagator) Extract(header http.Header) (TraceContext, bool) {
+	if p.SingleHeader {
+		if tc, ok := extractB3Single(header); ok {
+			return tc, true
+		}
+		return extractB3Multi(header)
+	}
+
+	if tc, ok := extractB3Multi(header); ok {
+		return tc, true
+	}
+	return extractB3Single(header)
+}
+
+//Inject writes the trace context to the B3 headers
+//	Nothing is written if the trace context has no trace ID.
+func (p B3Propagator) Inject(header http.Header, tc TraceContext) {
+	if tc.TraceID == "" {
+		return
+	}
+
+	traceID := b3TraceID(tc.TraceID)
+	spanID := w3cSpanID(tc.SpanID)
+	sampled := b3SampledDeny
+	if tc.Sampled() {
+		sampled = b3SampledAccept
+	}
+
+	if p.SingleHeader {
+		header.Set(headerB3, traceID+"-"+spanID+"-"+sampled)
+		return
+	}
+
+	header.Set(headerB3TraceID, traceID)
+	header.Set(headerB3SpanID, spanID)
+	header.Set(headerB3Sampled, sampled)
+}
+
+func extractB3Multi(header http.Header) (TraceContext, bool) {
+	traceID := strings.ToLower(header.Get(headerB3TraceID))
+	spanID := strings.ToLower(header.Get(headerB3SpanID))
+	if !isB3TraceID(traceID) || !isLowerHex(spanID, 16) {
+		return TraceContext{}, false
+	}
+
+	traceFlags := ""
+	if header.Get(headerB3Flags) == "1" {
+		traceFlags = sampledTraceFlags
+	} else {
+		switch strings.ToLower(header.Get(headerB3Sampled)) {
+		case b3SampledAccept, "true":
+			traceFlags = sampledTraceFlags
+		case b3SampledDeny, "false":
+			traceFlags = unsampledTraceFlags
+		}
+	}
+
+	return TraceContext{TraceID: traceID, SpanID: spanID, TraceFlags: traceFlags}, true
+}
+
+//extractB3Single reads the trace context from the single b3 header
+//	Format: {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}, where the last two fields are optional
+func extractB3Single(header http.Header) (TraceContext, bool) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(header.Get(headerB3))), "-")
+	//A single field only contains a sampling state and does not carry a trace context
+	if len(parts) < 2 || len(parts) > 4 {
+		return TraceContext{}, false
+	}
+
+	traceID, spanID := parts[0], parts[1]
+	if !isB3TraceID(traceID) || !isLowerHex(spanID, 16) {
+		return TraceContext{}, false
+	}
+
+	traceFlags := ""
+	if len(parts) > 2 {
+		switch parts[2] {
+		case b3SampledAccept, b3SampledDebug:
+			traceFlags = sampledTraceFlags
+		case b3SampledDeny:
+			traceFlags = unsampledTraceFlags
+		default:
+			return TraceContext{}, false
+		}
+	}
+	if len(parts) > 3 && !isLowerHex(parts[3], 16) {
+		return TraceContext{}, false
+	}
+
+	return TraceContext{TraceID: traceID, SpanID: spanID, TraceFlags: traceFlags}, true
+}
+
+//isB3TraceID returns true if value is a valid 64 or 128-bit B3 trace ID
+func isB3TraceID(value string) bool {
+	return (isLowerHex(value, 16) || isLowerHex(value, 32)) && !isZeroHex(value)
+}
+
+//b3TraceID converts a trace ID to a B3 trace ID
+//	Valid B3 trace IDs are preserved. Any other IDs are converted to the W3C format.
+func b3TraceID(id string) string {
+	if isB3TraceID(id) {
+		return id
+	}
+	return w3cTraceID(id)
+}
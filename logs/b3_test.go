@@ -0,0 +1,99 @@
+package logs
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestExtractB3Single(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  TraceContext
+		ok    bool
+	}{
+		{name: "128-bit trace id with parent", value: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90",
+			want: TraceContext{TraceID: "80f198ee56343ba864fe8b2a57d3eff7", SpanID: "e457b5a2e4d86bd1", TraceFlags: "01"}, ok: true},
+		{name: "64-bit trace id", value: "a3ce929d0e0e4736-e457b5a2e4d86bd1",
+			want: TraceContext{TraceID: "a3ce929d0e0e4736", SpanID: "e457b5a2e4d86bd1"}, ok: true},
+		{name: "deny", value: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-0",
+			want: TraceContext{TraceID: "80f198ee56343ba864fe8b2a57d3eff7", SpanID: "e457b5a2e4d86bd1", TraceFlags: "00"}, ok: true},
+		{name: "debug", value: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-d",
+			want: TraceContext{TraceID: "80f198ee56343ba864fe8b2a57d3eff7", SpanID: "e457b5a2e4d86bd1", TraceFlags: "01"}, ok: true},
+		{name: "uppercase is normalized", value: "80F198EE56343BA864FE8B2A57D3EFF7-E457B5A2E4D86BD1-1",
+			want: TraceContext{TraceID: "80f198ee56343ba864fe8b2a57d3eff7", SpanID: "e457b5a2e4d86bd1", TraceFlags: "01"}, ok: true},
+		{name: "sampling state only", value: "1"},
+		{name: "zero trace id", value: "00000000000000000000000000000000-e457b5a2e4d86bd1-1"},
+		{name: "short span id", value: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd-1"},
+		{name: "invalid sampling state", value: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-x"},
+		{name: "invalid parent id", value: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-xyz"},
+		{name: "extra field", value: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90-1"},
+		{name: "non-hex trace id", value: "80f198ee56343ba864fe8b2a57d3effg-e457b5a2e4d86bd1"},
+		{name: "empty", value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(headerB3, tt.value)
+
+			got, ok := extractB3Single(header)
+			if ok != tt.ok {
+				t.Fatalf("extractB3Single(%q) ok = %v, want %v", tt.value, ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("extractB3Single(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractB3Multi(t *testing.T) {
+	tests := []struct {
+		name    string
+		traceID string
+		spanID  string
+		sampled string
+		flags   string
+		want    TraceContext
+		ok      bool
+	}{
+		{name: "sampled", traceID: "80f198ee56343ba864fe8b2a57d3eff7", spanID: "e457b5a2e4d86bd1", sampled: "1",
+			want: TraceContext{TraceID: "80f198ee56343ba864fe8b2a57d3eff7", SpanID: "e457b5a2e4d86bd1", TraceFlags: "01"}, ok: true},
+		{name: "not sampled", traceID: "80f198ee56343ba864fe8b2a57d3eff7", spanID: "e457b5a2e4d86bd1", sampled: "0",
+			want: TraceContext{TraceID: "80f198ee56343ba864fe8b2a57d3eff7", SpanID: "e457b5a2e4d86bd1", TraceFlags: "00"}, ok: true},
+		{name: "legacy true", traceID: "a3ce929d0e0e4736", spanID: "e457b5a2e4d86bd1", sampled: "true",
+			want: TraceContext{TraceID: "a3ce929d0e0e4736", SpanID: "e457b5a2e4d86bd1", TraceFlags: "01"}, ok: true},
+		{name: "debug flag overrides sampled", traceID: "a3ce929d0e0e4736", spanID: "e457b5a2e4d86bd1", sampled: "0", flags: "1",
+			want: TraceContext{TraceID: "a3ce929d0e0e4736", SpanID: "e457b5a2e4d86bd1", TraceFlags: "01"}, ok: true},
+		{name: "no sampling decision", traceID: "a3ce929d0e0e4736", spanID: "e457b5a2e4d86bd1",
+			want: TraceContext{TraceID: "a3ce929d0e0e4736", SpanID: "e457b5a2e4d86bd1"}, ok: true},
+		{name: "uppercase is normalized", traceID: "80F198EE56343BA864FE8B2A57D3EFF7", spanID: "E457B5A2E4D86BD1",
+			want: TraceContext{TraceID: "80f198ee56343ba864fe8b2a57d3eff7", SpanID: "e457b5a2e4d86bd1"}, ok: true},
+		{name: "zero trace id", traceID: "0000000000000000", spanID: "e457b5a2e4d86bd1"},
+		{name: "missing span id", traceID: "80f198ee56343ba864fe8b2a57d3eff7"},
+		{name: "missing trace id", spanID: "e457b5a2e4d86bd1"},
+		{name: "invalid trace id length", traceID: "80f198ee56343ba864fe8b2a57", spanID: "e457b5a2e4d86bd1"},
+		{name: "non-hex span id", traceID: "80f198ee56343ba864fe8b2a57d3eff7", spanID: "e457b5a2e4d86bdz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for name, value := range map[string]string{headerB3TraceID: tt.traceID, headerB3SpanID: tt.spanID,
+				headerB3Sampled: tt.sampled, headerB3Flags: tt.flags} {
+				if value != "" {
+					header.Set(name, value)
+				}
+			}
+
+			got, ok := extractB3Multi(header)
+			if ok != tt.ok {
+				t.Fatalf("extractB3Multi() ok = %v, want %v", ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("extractB3Multi() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+const defaultMaxBodyBytes = 4096
+
+//BodyCaptureOpts provides configuration options for capturing request and response bodies in the request logs
+//	Captured bodies are printed with "Request Complete" and are subject to the SensitiveFields and scrubbing settings of
+//	the Logger. JSON bodies which are captured in full are logged as structured data so that SensitiveFields paths apply.
+type BodyCaptureOpts struct {
+	//Request: When true, request bodies are captured
+	Request bool
+	//Response: When true, response bodies are captured. The response writer must be wrapped using WrapResponseWriter.
+	Response bool
+	//MaxBytes: The maximum number of bytes of each body to be captured. Longer bodies are truncated.
+	//			Default: 4096
+	MaxBytes int
+	//ContentTypes: A list of media types of bodies which should be captured. Names may be glob patterns (eg. "text/*")
+	//				Defaults: application/json, application/*+json, application/x-www-form-urlencoded, text/*
+	ContentTypes []string
+}
+
+//bodyCapture contains the settings used to capture a body
+type bodyCapture struct {
+	request      bool
+	response     bool
+	maxBytes     int
+	contentTypes *nameMatcher
+}
+
+func newBodyCapture(opts *BodyCaptureOpts) *bodyCapture {
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBodyBytes
+	}
+
+	contentTypes := opts.ContentTypes
+	if contentTypes == nil {
+		contentTypes = []string{"application/json", "application/*+json", "application/x-www-form-urlencoded", "text/*"}
+	}
+	matcher, _ := newNameMatcher(contentTypes, nil)
+
+	return &bodyCapture{request: opts.Request, response: opts.Response, maxBytes: maxBytes, contentTypes: matcher}
+}
+
+//allowed returns true if bodies of the provided content type should be captured
+func (c *bodyCapture) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return c.contentTypes.Match(mediaType)
+}
+
+//capturedBody contains a captured request or response body
+type capturedBody struct {
+	data        bytes.Buffer
+	truncated   bool
+	contentType string
+}
+
+//write captures up to maxBytes of data in total
+func (b *capturedBody) write(p []byte, maxBytes int) {
+	remaining := maxBytes - b.data.Len()
+	if len(p) > remaining {
+		p = p[:remaining]
+		b.truncated = true
+	}
+	b.data.Write(p)
+}
+
+//logValue returns the value to be logged for the body
+//	Complete JSON bodies are decoded so that they can be redacted by key path. All other bodies are logged as strings.
+func (b *capturedBody) logValue() interface{} {
+	if !b.truncated {
+		if mediaType, _, err := mime.ParseMediaType(b.contentType); err == nil && isJSONMediaType(mediaType) {
+			var value interface{}
+			if err := json.Unmarshal(b.data.Bytes(), &value); err == nil {
+				return value
+			}
+		}
+	}
+	return b.data.String()
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || (len(mediaType) > 5 && mediaType[len(mediaType)-5:] == "+json")
+}
+
+//CaptureBodies enables capturing the request and response bodies of a request to be printed with "Request Complete"
+//	The request body is read up to the configured limit and r.Body is restored so that it can still be read in full
+//	by the handler. Response bodies are only captured if the response writer was wrapped using WrapResponseWriter.
+//	Params:
+//		r: The active request
+//		opts: Configuration options for the body capture
+func (l *Log) CaptureBodies(r *http.Request, opts *BodyCaptureOpts) {
+	if l == nil || opts == nil {
+		return
+	}
+
+	l.captureBodies(r, newBodyCapture(opts))
+}
+
+func (l *Log) captureBodies(r *http.Request, capture *bodyCapture) {
+	if l == nil || capture == nil {
+		return
+	}
+
+	if capture.request && r != nil && r.Body != nil && r.Body != http.NoBody {
+		contentType := r.Header.Get("Content-Type")
+		if capture.allowed(contentType) {
+			body := &capturedBody{contentType: contentType}
+			data, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(capture.maxBytes)+1))
+			body.write(data, capture.maxBytes)
+			if err != nil {
+				l.SetContext("request_body_error", err.Error())
+			}
+
+			//Restore the body with the bytes which have already been read
+			r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), r.Body), Closer: r.Body}
+			l.requestBody = body
+		}
+	}
+
+	if capture.response && l.response != nil {
+		l.response.capture = capture
+	}
+}
+
+//readCloser combines an io.Reader and an io.Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+//captureResponse captures the response body written to the recorder if its content type is allowed
+func (r *responseRecorder) captureResponse(p []byte) {
+	if r.capture == nil || len(p) == 0 {
+		return
+	}
+
+	if r.body == nil {
+		contentType := r.w.Header().Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(p)
+		}
+		if !r.capture.allowed(contentType) {
+			r.capture = nil
+			return
+		}
+		r.body = &capturedBody{contentType: contentType}
+	}
+
+	if !r.body.truncated {
+		r.body.write(p, r.capture.maxBytes)
+	}
+}
+
+//captureWriter passes writes through to the recorder body capture
+type captureWriter struct {
+	rec *responseRecorder
+}
+
+func (c captureWriter) Write(p []byte) (int, error) {
+	c.rec.captureResponse(p)
+	return len(p), nil
+}
@@ -0,0 +1,148 @@
+package logs
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rokmetro/logging-library/logutils"
+	"github.com/sirupsen/logrus"
+)
+
+//bufferedEntry is a log entry which has been buffered to be printed later
+type bufferedEntry struct {
+	level   logrus.Level
+	time    time.Time
+	message string
+	fields  logutils.Fields
+}
+
+//logBuffer holds the buffered Debug and Info entries of a request
+type logBuffer struct {
+	lock     sync.Mutex
+	entries  []bufferedEntry
+	size     int
+	dropped  int
+	hasError bool
+}
+
+func newLogBuffer(size int) *logBuffer {
+	return &logBuffer{size: size}
+}
+
+//add buffers the entry, dropping the oldest entry if the buffer is full
+func (b *logBuffer) add(entry bufferedEntry) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.entries) >= b.size {
+		b.entries = b.entries[1:]
+		b.dropped++
+	}
+	b.entries = append(b.entries, entry)
+}
+
+//setError records that an error level log was printed for the request
+func (b *logBuffer) setError() {
+	b.lock.Lock()
+	b.hasError = true
+	b.lock.Unlock()
+}
+
+//drain returns and clears the buffered entries, the number of dropped entries and whether an error was logged
+func (b *logBuffer) drain() ([]bufferedEntry, int, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	entries, dropped := b.entries, b.dropped
+	b.entries = nil
+	b.dropped = 0
+	return entries, dropped, b.hasError
+}
+
+//root returns the request log of a span, or the log itself if it is not a span
+func (l *Log) root() *Log {
+	root := l
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+//buffered returns true if the Debug and Info logs of the request are buffered
+func (l *Log) buffered() bool {
+	return l.root().buffer != nil
+}
+
+//output prints a log entry at the provided level
+//	If the request logs are buffered, Debug and Info entries are buffered to be printed by RequestComplete instead.
+//	Otherwise, Debug and Info entries are discarded if the request was not sampled.
+func (l *Log) output(level logrus.Level, message string, fields logutils.Fields) {
+	root := l.root()
+	buffer := root.buffer
+	if buffer == nil && !root.sampled && level >= logrus.InfoLevel {
+		return
+	}
+
+	if buffer != nil {
+		if level >= logrus.InfoLevel {
+			buffer.add(bufferedEntry{level: level, time: time.Now(), message: message, fields: fields})
+			return
+		}
+		if level <= logrus.ErrorLevel {
+			buffer.setError()
+		}
+	}
+
+	l.write(level, message, fields)
+}
+
+//write prints a log entry at the provided level
+func (l *Log) write(level logrus.Level, message string, fields logutils.Fields) {
+	l.markLogged()
+	l.logger.entry.WithFields(fields.ToMap()).Log(level, message)
+}
+
+//markLogged records that the log and all of its parents have printed a log
+//	Spans of the same request may print logs from different goroutines, so the flag is set atomically.
+func (l *Log) markLogged() {
+	for log := l; log != nil; log = log.parent {
+		atomic.StoreInt32(&log.hasLogged, 1)
+	}
+}
+
+//logged returns true if the log or any of its spans has printed a log
+func (l *Log) logged() bool {
+	return atomic.LoadInt32(&l.hasLogged) == 1
+}
+
+//flushBuffer prints the buffered entries of the request if it logged an error or failed with a status code >= 500
+//	Buffered entries are printed regardless of the level of the Logger. Otherwise, the entries are discarded.
+//	Returns the number of buffered entries which were dropped because the buffer was full, and whether the buffered
+//	entries were discarded.
+func (l *Log) flushBuffer(statusCode int) (int, bool) {
+	if l.buffer == nil {
+		return 0, false
+	}
+
+	entries, dropped, hasError := l.buffer.drain()
+	if !hasError && statusCode < http.StatusInternalServerError {
+		return 0, true
+	}
+
+	if len(entries) > 0 {
+		l.markLogged()
+	}
+	for _, entry := range entries {
+		l.logger.bufferEntry.WithFields(entry.fields.ToMap()).WithTime(entry.time).Log(entry.level, entry.message)
+	}
+	return dropped, false
+}
+
+//newBufferLogger creates a logger which shares the output, formatter and hooks of base but prints all levels
+//	It is used to print buffered Debug entries when the level of base is higher
+func newBufferLogger(base *logrus.Logger) *logrus.Logger {
+	return &logrus.Logger{Out: base.Out, Formatter: base.Formatter, Hooks: base.Hooks, Level: logrus.TraceLevel,
+		ExitFunc: base.ExitFunc, ReportCaller: base.ReportCaller}
+}
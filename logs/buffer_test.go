@@ -0,0 +1,121 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//lockedBuffer is a bytes.Buffer which can be written to concurrently
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+//entries returns the JSON log entries written to the buffer
+func (b *lockedBuffer) entries(t *testing.T) []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log entry %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+//newTestLogger creates a Logger which writes JSON logs to the returned buffer
+func newTestLogger(opts LoggerOpts) (*Logger, *lockedBuffer) {
+	buf := &lockedBuffer{}
+	opts.Sinks = []Sink{{Type: SinkWriter, Writer: buf, Format: FormatJSON}}
+	return NewLogger("test", &opts), buf
+}
+
+func TestSpansMarkLoggedConcurrently(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	logger, buf := newTestLogger(LoggerOpts{})
+	log, _ := logger.NewRequestLogWithContext(httptest.NewRequest("GET", "/", nil))
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			resp, err := client.Do(req.WithContext(NewContext(req.Context(), log)))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if !log.logged() {
+		t.Error("logged() = false after spans printed logs, want true")
+	}
+	if got := len(buf.entries(t)); got != 4 {
+		t.Errorf("got %d entries, want 4", got)
+	}
+}
+
+func TestRequestCompleteBuffered(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		logError    bool
+		wantEntries int
+		wantRequest bool
+	}{
+		{name: "success discards buffer", status: http.StatusOK, wantEntries: 1, wantRequest: true},
+		{name: "server error flushes buffer", status: http.StatusInternalServerError, wantEntries: 3},
+		{name: "error log flushes buffer", status: http.StatusOK, logError: true, wantEntries: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newTestLogger(LoggerOpts{RequestLogBuffer: 10})
+			log := logger.NewRequestLog(httptest.NewRequest("GET", "/users/1", nil))
+			log.RequestReceived()
+			log.Info("handling")
+			if tt.logError {
+				log.Error("failed")
+			}
+			log.SetContext("status_code", tt.status)
+			log.RequestComplete()
+
+			entries := buf.entries(t)
+			if len(entries) != tt.wantEntries {
+				t.Fatalf("got %d entries, want %d", len(entries), tt.wantEntries)
+			}
+			complete := entries[len(entries)-1]
+			if complete["msg"] != "Request Complete" {
+				t.Fatalf("last entry = %q, want Request Complete", complete["msg"])
+			}
+			if _, ok := complete["request"]; ok != tt.wantRequest {
+				t.Errorf("request field present = %v, want %v", ok, tt.wantRequest)
+			}
+		})
+	}
+}
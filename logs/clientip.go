@@ -0,0 +1,67 @@
+package logs
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+//clientIP returns the IP address of the client which sent the request
+//	Forwarding headers are only used if the request was received from one of the trusted proxies. The forwarded
+//	addresses are then read from right to left, skipping any trusted proxies, and the first untrusted address is used.
+//	Headers are used in order of precedence: Forwarded (RFC 7239), X-Forwarded-For, X-Real-IP
+func (l *Logger) clientIP(r *http.Request) string {
+	remoteIP := remoteHost(r.RemoteAddr)
+	if len(l.trustedProxies) == 0 || !matchCIDRs(l.trustedProxies, net.ParseIP(remoteIP)) {
+		return remoteIP
+	}
+
+	var hops []string
+	if forwarded := r.Header.Values("Forwarded"); len(forwarded) > 0 {
+		hops = parseForwarded(strings.Join(forwarded, ","))
+	} else if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops = strings.Split(strings.Join(xff, ","), ",")
+	} else if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		hops = []string{realIP}
+	}
+
+	clientIP := remoteIP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(stripPort(strings.TrimSpace(hops[i])))
+		if ip == nil {
+			//Obfuscated or unknown addresses cannot be followed any further
+			break
+		}
+
+		clientIP = ip.String()
+		if !matchCIDRs(l.trustedProxies, ip) {
+			break
+		}
+	}
+	return clientIP
+}
+
+//parseForwarded returns the "for" addresses of each element in an RFC 7239 Forwarded header value
+//	Format: for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"
+func parseForwarded(value string) []string {
+	var hops []string
+	for _, element := range strings.Split(value, ",") {
+		hop := ""
+		for _, pair := range strings.Split(element, ";") {
+			keyValue := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(keyValue) == 2 && strings.EqualFold(keyValue[0], "for") {
+				hop = strings.Trim(keyValue[1], `"`)
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+//stripPort removes the port and any brackets from an address (eg. "[2001:db8::1]:80", "192.0.2.1:80")
+func stripPort(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+}
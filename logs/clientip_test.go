@@ -0,0 +1,75 @@
+package logs
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseForwarded(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "single element", value: "for=192.0.2.60;proto=http;by=203.0.113.43", want: []string{"192.0.2.60"}},
+		{name: "multiple elements", value: "for=192.0.2.43, for=198.51.100.17", want: []string{"192.0.2.43", "198.51.100.17"}},
+		{name: "quoted IPv6 with port", value: `for="[2001:db8:cafe::17]:4711"`, want: []string{"[2001:db8:cafe::17]:4711"}},
+		{name: "case-insensitive key", value: "For=192.0.2.60", want: []string{"192.0.2.60"}},
+		{name: "obfuscated identifier", value: "for=_hidden, for=unknown", want: []string{"_hidden", "unknown"}},
+		{name: "missing for", value: "proto=https;by=203.0.113.43", want: []string{""}},
+		{name: "for without value", value: "for", want: []string{""}},
+		{name: "empty for", value: "for=", want: []string{""}},
+		{name: "malformed for", value: "for192.0.2.60;proto=http", want: []string{""}},
+		{name: "empty element", value: "for=192.0.2.60,,for=198.51.100.17", want: []string{"192.0.2.60", "", "198.51.100.17"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseForwarded(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseForwarded(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	logger := NewLogger("test", &LoggerOpts{TrustedProxies: []string{"10.0.0.0/8"}})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{name: "no headers", remoteAddr: "10.0.0.1:1234", want: "10.0.0.1"},
+		{name: "untrusted remote ignores headers", remoteAddr: "192.0.2.1:1234",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.17"}, want: "192.0.2.1"},
+		{name: "x-forwarded-for skips trusted proxies", remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.5, 198.51.100.17, 10.0.0.2"}, want: "198.51.100.17"},
+		{name: "forwarded takes precedence", remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": `for="[2001:db8::17]:4711"`, "X-Forwarded-For": "198.51.100.17"}, want: "2001:db8::17"},
+		{name: "malformed forwarded for stops at the proxy", remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": "for=198.51.100.17, for192.0.2.60"}, want: "10.0.0.1"},
+		{name: "obfuscated forwarded for stops at the proxy", remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"Forwarded": "for=_hidden"}, want: "10.0.0.1"},
+		{name: "x-real-ip", remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"X-Real-IP": "198.51.100.17"}, want: "198.51.100.17"},
+		{name: "invalid x-real-ip", remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"X-Real-IP": "not-an-ip"}, want: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+
+			if got := logger.clientIP(r); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
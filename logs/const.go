@@ -30,3 +30,79 @@ const (
 	Warn  logLevel = "Warn"
 	Error logLevel = "Error"
 )
+
+//PropagationFormat represents a format used to propagate trace context between services in HTTP headers
+type PropagationFormat string
+
+const (
+	//Propagation formats
+	PropagationLegacy   PropagationFormat = "legacy"    //trace-id and span-id headers
+	PropagationW3C      PropagationFormat = "w3c"       //W3C Trace Context traceparent and tracestate headers
+	PropagationB3       PropagationFormat = "b3"        //Zipkin B3 X-B3-* headers
+	PropagationB3Single PropagationFormat = "b3-single" //Zipkin B3 single b3 header
+)
+
+//SpanStatus represents the final status of a span
+type SpanStatus string
+
+const (
+	//Span statuses
+	SpanStatusUnset SpanStatus = "Unset"
+	SpanStatusOk    SpanStatus = "Ok"
+	SpanStatusError SpanStatus = "Error"
+)
+
+//ScrubDetector represents a built-in detector for sensitive data which should be scrubbed from logs
+type ScrubDetector string
+
+const (
+	//Scrub detectors
+	ScrubEmail       ScrubDetector = "email"        //Email addresses
+	ScrubJWT         ScrubDetector = "jwt"          //JSON Web Tokens
+	ScrubBearerToken ScrubDetector = "bearer_token" //Bearer tokens (eg. "Bearer abc123")
+	ScrubCreditCard  ScrubDetector = "credit_card"  //Credit card numbers which pass the Luhn check
+	ScrubSSN         ScrubDetector = "ssn"          //US social security numbers
+	ScrubAWSKey      ScrubDetector = "aws_key"      //AWS access key IDs and secret access keys
+)
+
+//RedactionMode represents the way sensitive field values are redacted
+type RedactionMode string
+
+const (
+	//Redaction modes
+	RedactMask RedactionMode = "mask" //Replace the value with "---"
+	RedactHash RedactionMode = "hash" //Replace the value with a truncated SHA-256 hash so equal values can still be correlated
+	RedactDrop RedactionMode = "drop" //Remove the field entirely
+)
+
+//SinkType represents a destination logs are written to
+type SinkType string
+
+const (
+	//Sink types
+	SinkStdout SinkType = "stdout" //Standard output
+	SinkStderr SinkType = "stderr" //Standard error
+	SinkFile   SinkType = "file"   //A file which logs are appended to
+	SinkWriter SinkType = "writer" //An arbitrary io.Writer
+)
+
+//OutputFormat represents the format logs are written in
+type OutputFormat string
+
+const (
+	//Output formats
+	FormatText OutputFormat = "text" //logrus text format
+	FormatJSON OutputFormat = "json" //logrus JSON format
+	FormatECS  OutputFormat = "ecs"  //Elastic Common Schema JSON
+	FormatOTel OutputFormat = "otel" //OpenTelemetry OTLP/JSON logs
+)
+
+//OverflowPolicy represents the behavior of the async log queue when it is full
+type OverflowPolicy string
+
+const (
+	//Overflow policies
+	OverflowBlock         OverflowPolicy = "block"           //Wait until there is room in the queue
+	OverflowDropNewest    OverflowPolicy = "drop_newest"     //Drop the entry being logged
+	OverflowDropDebugInfo OverflowPolicy = "drop_debug_info" //Drop queued Debug and Info entries first to make room for Warn and Error entries
+)
@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rokmetro/logging-library/logutils"
+)
+
+//logContextKey is the key used to store a Log in a context.Context
+type logContextKey struct{}
+
+//NewContext returns a copy of ctx which carries the provided Log
+func NewContext(ctx context.Context, l *Log) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, logContextKey{}, l)
+}
+
+//FromContext returns the Log carried by ctx
+//	If ctx does not carry a Log, a no-op Log is returned which discards all logs, so the result is always safe to use
+func FromContext(ctx context.Context) *Log {
+	if l, ok := logFromContext(ctx); ok {
+		return l
+	}
+	return &Log{context: logutils.Fields{}}
+}
+
+//FromContext returns the Log carried by ctx
+//	If ctx does not carry a Log, a new base Log for the Logger is returned with a new trace ID
+func (l *Logger) FromContext(ctx context.Context) *Log {
+	if log, ok := logFromContext(ctx); ok {
+		return log
+	}
+	return l.NewLog("", RequestContext{})
+}
+
+//NewRequestLogWithContext is a constructor for a log object for a request which is also stored on the request context
+//	Returns the Log and a shallow copy of r whose context carries the Log. The returned request should be passed to
+//	the handler so that FromContext(r.Context()) can be used by any code handling the request.
+func (l *Logger) NewRequestLogWithContext(r *http.Request) (*Log, *http.Request) {
+	log := l.NewRequestLog(r)
+	if r == nil {
+		return log, nil
+	}
+
+	return log, r.WithContext(NewContext(r.Context(), log))
+}
+
+func logFromContext(ctx context.Context) (*Log, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	l, ok := ctx.Value(logContextKey{}).(*Log)
+	return l, ok && l != nil
+}
@@ -0,0 +1,144 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//ecsVersion is the version of the Elastic Common Schema logs are formatted with
+const ecsVersion = "8.11.0"
+
+//ecsFields maps the fields added by the library to their Elastic Common Schema fields
+var ecsFields = map[string]string{
+	"service_name":   "service.name",
+	"trace_id":       "trace.id",
+	"span_id":        "span.id",
+	"parent_span_id": "parent.id",
+	"function_name":  "log.origin.function",
+	"status_code":    "http.response.status_code",
+	"response_size":  "http.response.body.bytes",
+	"error":          "error.message",
+	"stack":          "error.stack_trace",
+}
+
+//ecsFormatter is a logrus formatter which formats entries as Elastic Common Schema (ECS) JSON
+//	Fields added by the library are mapped to their ECS fields. Other fields are kept with their original keys.
+type ecsFormatter struct{}
+
+//Format formats the entry as an ECS JSON document
+func (f *ecsFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	doc := map[string]interface{}{
+		"@timestamp":  entry.Time.UTC().Format(time.RFC3339Nano),
+		"message":     entry.Message,
+		"ecs.version": ecsVersion,
+		"log.level":   entry.Level.String(),
+	}
+
+	for key, value := range entry.Data {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+
+		switch key {
+		case "request":
+			if request, ok := value.(RequestContext); ok {
+				addECSRequest(doc, request)
+				continue
+			}
+		case "duration_ms":
+			if duration, ok := value.(float64); ok {
+				doc["event.duration"] = int64(duration * float64(time.Millisecond))
+				continue
+			}
+		case "panic":
+			if _, ok := entry.Data["error"]; !ok {
+				doc["error.message"] = fmt.Sprint(value)
+				doc["error.type"] = "panic"
+				continue
+			}
+		}
+
+		if ecsKey, ok := ecsFields[key]; ok {
+			key = ecsKey
+		}
+		doc[key] = value
+	}
+
+	serialized, err := json.Marshal(nestECSFields(doc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to ECS JSON, %v", err)
+	}
+	return append(serialized, '\n'), nil
+}
+
+//addECSRequest adds the fields of request to doc
+//	Parts of the request without an ECS field are kept under "request".
+func addECSRequest(doc map[string]interface{}, request RequestContext) {
+	doc["http.request.method"] = request.Method
+	doc["url.path"] = request.Path
+	if request.ClientIP != "" {
+		doc["client.ip"] = request.ClientIP
+	}
+	if request.Route != "" {
+		doc["http.route"] = request.Route
+	}
+
+	other := map[string]interface{}{"headers": request.Headers}
+	if request.Query != nil {
+		other["query"] = request.Query
+	}
+	if request.OmittedHeaders > 0 {
+		other["omitted_headers"] = request.OmittedHeaders
+	}
+	if request.PrevSpanID != "" {
+		other["prev_span_id"] = request.PrevSpanID
+	}
+	doc["request"] = other
+}
+
+//ecsObject is a JSON object created for the dotted keys of an ECS document
+type ecsObject map[string]interface{}
+
+//nestECSFields converts the dotted keys of doc into nested objects (eg. "trace.id" to {"trace": {"id": ...}})
+//	Keys which conflict with another value are kept as they are.
+func nestECSFields(doc map[string]interface{}) map[string]interface{} {
+	nested := map[string]interface{}{}
+	for key, value := range doc {
+		if !strings.Contains(key, ".") {
+			nested[key] = value
+		}
+	}
+
+	for key, value := range doc {
+		if !strings.Contains(key, ".") {
+			continue
+		}
+
+		parts := strings.Split(key, ".")
+		current := ecsObject(nested)
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := current[part].(ecsObject)
+			if !ok {
+				if _, exists := current[part]; exists {
+					current = nil
+					break
+				}
+				child = ecsObject{}
+				current[part] = child
+			}
+			current = child
+		}
+
+		last := parts[len(parts)-1]
+		if _, exists := current[last]; current == nil || exists {
+			nested[key] = value
+			continue
+		}
+		current[last] = value
+	}
+	return nested
+}
@@ -0,0 +1,176 @@
+package logs
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const redactedValue = "---"
+
+//nameMatcher matches names case-insensitively against a list of exact names, glob patterns and regular expressions
+type nameMatcher struct {
+	names   map[string]bool
+	globs   []string
+	regexes []*regexp.Regexp
+}
+
+//newNameMatcher creates a nameMatcher
+//	names: Exact names or glob patterns (eg. "*-Token", "X-Amz-*") to be matched
+//	patterns: Regular expressions to be matched
+//	Returns the matcher and any patterns that could not be compiled
+func newNameMatcher(names []string, patterns []string) (*nameMatcher, []string) {
+	m := &nameMatcher{names: map[string]bool{}}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if strings.ContainsAny(name, "*?[") {
+			m.globs = append(m.globs, name)
+		} else {
+			m.names[name] = true
+		}
+	}
+
+	var invalid []string
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			invalid = append(invalid, pattern)
+			continue
+		}
+		m.regexes = append(m.regexes, regex)
+	}
+	return m, invalid
+}
+
+//Match returns true if the name matches any of the names or patterns of the matcher
+func (m *nameMatcher) Match(name string) bool {
+	if m == nil {
+		return false
+	}
+
+	lower := strings.ToLower(name)
+	if m.names[lower] {
+		return true
+	}
+	for _, glob := range m.globs {
+		if ok, _ := path.Match(glob, lower); ok {
+			return true
+		}
+	}
+	for _, regex := range m.regexes {
+		if regex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+//redactHeaders returns a copy of the provided headers with the values of any sensitive headers hidden
+//	The values of sensitive cookies in the Cookie and Set-Cookie headers are hidden individually.
+//	If an allowlist is configured, only allowed headers are included and the number of omitted headers is returned.
+func (l *Logger) redactHeaders(header http.Header) (map[string][]string, int) {
+	headers := make(map[string][]string)
+	omitted := 0
+	for key, value := range header {
+		if l.allowedHeaders != nil && !l.allowedHeaders.Match(key) {
+			omitted++
+			continue
+		}
+
+		var logValue []string
+		//do not log sensitive information
+		switch {
+		case l.sensitiveHeaders.Match(key):
+			logValue = append(logValue, redactedValue)
+		case http.CanonicalHeaderKey(key) == "Cookie":
+			for _, v := range value {
+				logValue = append(logValue, redactCookies(v, l.sensitiveCookies))
+			}
+		case http.CanonicalHeaderKey(key) == "Set-Cookie":
+			for _, v := range value {
+				logValue = append(logValue, redactSetCookie(v, l.sensitiveCookies))
+			}
+		default:
+			logValue = value
+		}
+		headers[key] = truncateValues(logValue, l.maxHeaderLength)
+	}
+	return headers, omitted
+}
+
+//redactQuery parses the provided raw query string and hides the values of any sensitive query parameters
+//	Returns nil if the query string is empty
+func (l *Logger) redactQuery(rawQuery string) map[string][]string {
+	if rawQuery == "" {
+		return nil
+	}
+
+	//Parameters which cannot be parsed are ignored, so the error is not checked
+	params, _ := url.ParseQuery(rawQuery)
+	query := make(map[string][]string, len(params))
+	for key, value := range params {
+		if l.sensitiveQuery.Match(key) {
+			query[key] = []string{redactedValue}
+		} else {
+			query[key] = value
+		}
+	}
+	return query
+}
+
+//redactCookies hides the values of any sensitive cookies in a Cookie header value
+//	Format: name1=value1; name2=value2
+func redactCookies(value string, sensitiveCookies *nameMatcher) string {
+	cookies := strings.Split(value, ";")
+	for i, cookie := range cookies {
+		cookies[i] = redactCookie(strings.TrimSpace(cookie), sensitiveCookies)
+	}
+	return strings.Join(cookies, "; ")
+}
+
+//redactSetCookie hides the value of a sensitive cookie in a Set-Cookie header value
+//	Format: name=value; Attribute1; Attribute2=value2
+func redactSetCookie(value string, sensitiveCookies *nameMatcher) string {
+	parts := strings.SplitN(value, ";", 2)
+	parts[0] = redactCookie(strings.TrimSpace(parts[0]), sensitiveCookies)
+	return strings.Join(parts, ";")
+}
+
+func redactCookie(cookie string, sensitiveCookies *nameMatcher) string {
+	nameValue := strings.SplitN(cookie, "=", 2)
+	if len(nameValue) != 2 || !sensitiveCookies.Match(nameValue[0]) {
+		return cookie
+	}
+	return nameValue[0] + "=" + redactedValue
+}
+
+//truncateValues returns a copy of values with each value truncated to maxLength bytes
+//	Values are not truncated if maxLength <= 0
+func truncateValues(values []string, maxLength int) []string {
+	if maxLength <= 0 {
+		return values
+	}
+
+	truncated := make([]string, len(values))
+	for i, value := range values {
+		truncated[i] = truncateString(value, maxLength)
+	}
+	return truncated
+}
+
+//truncateString truncates value to at most maxLength bytes without splitting a UTF-8 character
+//	"..." is appended to truncated values
+func truncateString(value string, maxLength int) string {
+	if maxLength <= 0 || len(value) <= maxLength {
+		return value
+	}
+
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+	return value[:end] + "..."
+}
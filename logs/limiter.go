@@ -0,0 +1,269 @@
+package logs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//maxDedupKeys is the number of distinct entries tracked for deduplication before expired entries are removed
+const maxDedupKeys = 10000
+
+//LimiterOpts provides configuration options for rate limiting and deduplication of logs
+//	Fatal logs are never limited.
+type LimiterOpts struct {
+	//DedupWindow: Identical entries (same level, message and function name) printed within this duration of the first
+	//			   are suppressed, and a "N similar messages suppressed" summary is printed at the end of the window
+	//			   "Request Received", "Request Complete" and "Span Complete" are never deduplicated
+	//			   Deduplication is disabled if this is 0
+	DedupWindow time.Duration
+	//LevelLimits: Token bucket caps on the number of entries printed per level, including dedup summaries
+	//			   Entries exceeding the cap are suppressed, and a "N messages suppressed by rate limit" summary is
+	//			   printed once per DedupWindow, or per second if DedupWindow is 0
+	LevelLimits []LevelLimit
+}
+
+//LevelLimit defines the maximum rate of entries printed at a level
+type LevelLimit struct {
+	//Level: The level to limit
+	Level logLevel
+	//Rate: The number of entries per second which can be printed at the level
+	Rate float64
+	//Burst: The number of entries which can be printed at once before the rate applies (Default: Rate, at least 1)
+	Burst int
+}
+
+//limiter decides which log entries are printed based on the LimiterOpts of a Logger
+type limiter struct {
+	window          time.Duration
+	summaryInterval time.Duration
+	buckets         map[logrus.Level]*tokenBucket
+
+	mu          sync.Mutex
+	entries     map[dedupKey]*limitState
+	rateLimited map[logrus.Level]*limitState
+}
+
+//dedupKey identifies identical entries
+type dedupKey struct {
+	level    logrus.Level
+	message  string
+	function string
+}
+
+//limitState tracks the entries suppressed for a dedup key or a rate limited level
+type limitState struct {
+	start      time.Time
+	suppressed int
+	logger     *logrus.Logger
+	fields     logrus.Fields
+}
+
+func newLimiter(opts *LimiterOpts) *limiter {
+	summaryInterval := opts.DedupWindow
+	if summaryInterval <= 0 {
+		summaryInterval = time.Second
+	}
+
+	buckets := map[logrus.Level]*tokenBucket{}
+	for _, limit := range opts.LevelLimits {
+		level, ok := logrusLevel(limit.Level)
+		if !ok {
+			continue
+		}
+		buckets[level] = newTokenBucket(limit.Rate, limit.Burst)
+	}
+
+	return &limiter{window: opts.DedupWindow, summaryInterval: summaryInterval, buckets: buckets,
+		entries: map[dedupKey]*limitState{}, rateLimited: map[logrus.Level]*limitState{}}
+}
+
+//allow returns true if the entry should be printed
+func (l *limiter) allow(entry *logrus.Entry) bool {
+	if entry.Level <= logrus.FatalLevel {
+		return true
+	}
+	if _, ok := entry.Data[rateLimitedField]; ok {
+		//Rate limit summaries are printed at most once per summary interval for each level
+		return true
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, isSummary := entry.Data[suppressedCountField]
+	var key dedupKey
+	dedup := l.window > 0 && !isSummary && !isLifecycleEntry(entry)
+	if dedup {
+		function, _ := entry.Data["function_name"].(string)
+		key = dedupKey{level: entry.Level, message: entry.Message, function: function}
+		state := l.entries[key]
+		if state != nil && now.Sub(state.start) < l.window {
+			state.suppressed++
+			if state.suppressed == 1 {
+				state.logger = entry.Logger
+				fields := summaryFields(entry, "service_name", "function_name")
+				fields["suppressed_message"] = entry.Message
+				state.fields = fields
+				time.AfterFunc(state.start.Add(l.window).Sub(now), func() {
+					l.flush(state, entry.Level, "%d similar messages suppressed", func() { l.removeEntry(key, state) })
+				})
+			}
+			return false
+		}
+	}
+
+	bucket := l.buckets[entry.Level]
+	if bucket != nil && !bucket.take(now) {
+		//Summaries of deduplicated entries which exceed the rate limit are merged into the rate limit summary
+		suppressed := 1
+		if count, ok := entry.Data[suppressedCountField].(int); ok {
+			suppressed = count
+		}
+		l.addRateLimited(entry, suppressed, now)
+		return false
+	}
+
+	if dedup {
+		if len(l.entries) >= maxDedupKeys {
+			l.pruneEntries(now)
+		}
+		//A previous state with suppressed entries is replaced here, and its summary is still printed by its timer
+		l.entries[key] = &limitState{start: now}
+	}
+	return true
+}
+
+//addRateLimited records entries suppressed by the rate limit of the level of entry
+//	The lock must be held by the caller.
+func (l *limiter) addRateLimited(entry *logrus.Entry, suppressed int, now time.Time) {
+	state := l.rateLimited[entry.Level]
+	if state == nil {
+		fields := summaryFields(entry, "service_name")
+		fields[rateLimitedField] = true
+		state = &limitState{start: now, logger: entry.Logger, fields: fields}
+		l.rateLimited[entry.Level] = state
+		level := entry.Level
+		time.AfterFunc(l.summaryInterval, func() {
+			l.flush(state, level, "%d messages suppressed by rate limit", func() { delete(l.rateLimited, level) })
+		})
+	}
+	state.suppressed += suppressed
+}
+
+//isLifecycleEntry returns true if entry is printed once for each request or span by the library
+//	These entries share their message and function name, so they are never deduplicated.
+func isLifecycleEntry(entry *logrus.Entry) bool {
+	switch entry.Message {
+	case "Request Received", "Request Complete", "Span Complete":
+		return true
+	default:
+		return false
+	}
+}
+
+//flush prints the summary of the entries suppressed for a state
+//	remove is called with the lock held to stop tracking the state
+func (l *limiter) flush(state *limitState, level logrus.Level, format string, remove func()) {
+	l.mu.Lock()
+	suppressed := state.suppressed
+	state.suppressed = 0
+	remove()
+	l.mu.Unlock()
+
+	if suppressed == 0 || state.logger == nil {
+		return
+	}
+
+	fields := logrus.Fields{suppressedCountField: suppressed}
+	for key, value := range state.fields {
+		fields[key] = value
+	}
+	state.logger.WithFields(fields).Log(level, fmt.Sprintf(format, suppressed))
+}
+
+//removeEntry stops tracking the dedup state of key if it has not been replaced
+func (l *limiter) removeEntry(key dedupKey, state *limitState) {
+	if l.entries[key] == state {
+		delete(l.entries, key)
+	}
+}
+
+//pruneEntries stops tracking dedup states whose window has ended and which have no suppressed entries
+func (l *limiter) pruneEntries(now time.Time) {
+	for key, state := range l.entries {
+		if state.suppressed == 0 && now.Sub(state.start) >= l.window {
+			delete(l.entries, key)
+		}
+	}
+}
+
+//suppressedCountField is the field containing the number of suppressed entries in a summary
+const suppressedCountField = "suppressed_count"
+
+//rateLimitedField is the field marking the summary of entries suppressed by a rate limit
+const rateLimitedField = "rate_limited"
+
+//summaryFields returns the fields of entry with the provided keys to be included in the summary of suppressed entries
+func summaryFields(entry *logrus.Entry, keys ...string) logrus.Fields {
+	fields := logrus.Fields{}
+	for _, key := range keys {
+		if value, ok := entry.Data[key]; ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+//tokenBucket limits the rate of events while allowing bursts
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = int(rate)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+//take returns true and consumes a token if one is available
+func (b *tokenBucket) take(now time.Time) bool {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+//limitFormatter is a logrus formatter which discards the entries suppressed by a limiter
+//	Formatting is the last step before an entry is written, so this applies to all entries printed by a Logger.
+type limitFormatter struct {
+	logrus.Formatter
+	limiter *limiter
+}
+
+//Format formats the entry using the wrapped formatter, or returns no output if the entry is suppressed
+func (f *limitFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.limiter.allow(entry) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
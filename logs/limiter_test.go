@@ -0,0 +1,133 @@
+package logs
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	type step struct {
+		after time.Duration
+		want  bool
+	}
+
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		steps []step
+	}{
+		{name: "burst then rate", rate: 2, burst: 2, steps: []step{
+			{0, true}, {0, true}, {0, false}, {250 * time.Millisecond, false}, {250 * time.Millisecond, true}, {0, false}}},
+		{name: "refill capped at burst", rate: 10, burst: 2, steps: []step{
+			{0, true}, {time.Minute, true}, {0, true}, {0, false}}},
+		{name: "default burst is rate", rate: 3, steps: []step{
+			{0, true}, {0, true}, {0, true}, {0, false}}},
+		{name: "default burst at least 1", rate: 0.5, steps: []step{
+			{0, true}, {0, false}, {time.Second, false}, {time.Second, true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTokenBucket(tt.rate, tt.burst)
+			now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			for i, s := range tt.steps {
+				now = now.Add(s.after)
+				if got := b.take(now); got != s.want {
+					t.Fatalf("step %d: take() = %v, want %v", i, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	const window = 50 * time.Millisecond
+
+	type summary struct {
+		message     string
+		count       float64
+		rateLimited bool
+	}
+
+	tests := []struct {
+		name          string
+		opts          LimiterOpts
+		log           func(logger *Logger)
+		wantPrinted   int
+		wantSummaries []summary
+	}{
+		{name: "dedup", opts: LimiterOpts{DedupWindow: window},
+			log: func(logger *Logger) {
+				for i := 0; i < 5; i++ {
+					logger.Warn("disk full")
+				}
+				logger.Warn("other")
+			},
+			wantPrinted: 2, wantSummaries: []summary{{message: "4 similar messages suppressed", count: 4}}},
+		{name: "different levels are not duplicates", opts: LimiterOpts{DedupWindow: window},
+			log: func(logger *Logger) {
+				logger.Warn("disk full")
+				logger.Error("disk full")
+			},
+			wantPrinted: 2},
+		{name: "lifecycle entries are not deduplicated", opts: LimiterOpts{DedupWindow: window},
+			log: func(logger *Logger) {
+				for i := 0; i < 3; i++ {
+					log := logger.NewRequestLog(httptest.NewRequest("GET", "/", nil))
+					log.RequestReceived()
+					log.RequestComplete()
+				}
+			},
+			wantPrinted: 6},
+		{name: "rate limit", opts: LimiterOpts{DedupWindow: window, LevelLimits: []LevelLimit{{Level: Info, Rate: 1, Burst: 2}}},
+			log: func(logger *Logger) {
+				for i := 0; i < 5; i++ {
+					logger.Info(string(rune('a' + i)))
+				}
+				logger.Warn("not limited")
+			},
+			wantPrinted: 3, wantSummaries: []summary{{message: "3 messages suppressed by rate limit", count: 3, rateLimited: true}}},
+		{name: "dedup summary counts against rate limit", opts: LimiterOpts{DedupWindow: window, LevelLimits: []LevelLimit{{Level: Warn, Rate: 0.001, Burst: 1}}},
+			log: func(logger *Logger) {
+				for i := 0; i < 4; i++ {
+					logger.Warn("disk full")
+				}
+			},
+			wantPrinted: 1, wantSummaries: []summary{{message: "3 messages suppressed by rate limit", count: 3, rateLimited: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			logger, buf := newTestLogger(LoggerOpts{Limiter: &opts})
+			tt.log(logger)
+			time.Sleep(4 * window)
+
+			printed := 0
+			var summaries []summary
+			for _, entry := range buf.entries(t) {
+				count, ok := entry[suppressedCountField].(float64)
+				if !ok {
+					printed++
+					continue
+				}
+				rateLimited, _ := entry[rateLimitedField].(bool)
+				summaries = append(summaries, summary{message: entry["msg"].(string), count: count, rateLimited: rateLimited})
+			}
+
+			if printed != tt.wantPrinted {
+				t.Errorf("printed %d entries, want %d", printed, tt.wantPrinted)
+			}
+			if len(summaries) != len(tt.wantSummaries) {
+				t.Fatalf("summaries = %v, want %v", summaries, tt.wantSummaries)
+			}
+			for i := range summaries {
+				if summaries[i] != tt.wantSummaries[i] {
+					t.Errorf("summary %d = %v, want %v", i, summaries[i], tt.wantSummaries[i])
+				}
+			}
+		})
+	}
+}
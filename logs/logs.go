@@ -1,8 +1,14 @@
 package logs
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rokmetro/logging-library/errors"
@@ -38,13 +44,38 @@ func NewErrorHttpResponse(body string, code int) HttpResponse {
 
 //HttpRequestProperties is an entity which contains the properties of an HTTP request
 type HttpRequestProperties struct {
-	Method     string
-	Path       string
+	Method string
+	Path   string
+	//PathPrefix: A prefix of the request path (eg. "/health")
+	PathPrefix string
+	//PathGlob: A glob pattern matching the request path (eg. "/health*", "/static/**")
+	PathGlob string
+	//PathRegex: A regular expression matching the request path
+	PathRegex string
+	//Route: A glob pattern matching the route template of the request set using Log.SetRoute (eg. "/users/{id}")
+	Route string
+	//RemoteAddr: The remote address of the request, with or without the port
 	RemoteAddr string
-	UserAgent  string
+	//RemoteCIDRs: A list of CIDRs (eg. "10.0.0.0/8") or IP addresses, one of which must contain the remote IP of the request
+	RemoteCIDRs []string
+	//ClientCIDRs: A list of CIDRs or IP addresses, one of which must contain the client IP of the request
+	//			   The client IP is read from the forwarding headers when the request is received from a trusted proxy
+	ClientCIDRs []string
+	UserAgent   string
+	//UserAgentContains: A substring of the user agent (eg. "kube-probe")
+	UserAgentContains string
+	//Headers: Headers which must be present with a value matching the provided glob pattern ("*" matches any value)
+	Headers map[string]string
+}
+
+//Match returns true if all of the specified properties match the request
+//	Properties which require the route template do not match, since it is not known from the request alone.
+//	The remote IP of the request is used as the client IP, since no proxies are trusted.
+func (h HttpRequestProperties) Match(r *http.Request) bool {
+	return h.matchRequest(r, "", remoteHost(r.RemoteAddr))
 }
 
-func (h HttpRequestProperties) Match(r *http.Request) bool {
+func (h HttpRequestProperties) matchRequest(r *http.Request, route string, clientIP string) bool {
 	if h.Method != "" && h.Method != r.Method {
 		return false
 	}
@@ -53,7 +84,31 @@ func (h HttpRequestProperties) Match(r *http.Request) bool {
 		return false
 	}
 
-	if h.RemoteAddr != "" && h.RemoteAddr != r.RemoteAddr {
+	if h.PathPrefix != "" && !strings.HasPrefix(r.URL.Path, h.PathPrefix) {
+		return false
+	}
+
+	if h.PathGlob != "" && !matchGlob(h.PathGlob, r.URL.Path) {
+		return false
+	}
+
+	if h.PathRegex != "" && !matchRegexp(h.PathRegex, r.URL.Path) {
+		return false
+	}
+
+	if h.Route != "" && (route == "" || !matchGlob(h.Route, route)) {
+		return false
+	}
+
+	if h.RemoteAddr != "" && h.RemoteAddr != r.RemoteAddr && h.RemoteAddr != remoteHost(r.RemoteAddr) {
+		return false
+	}
+
+	if len(h.RemoteCIDRs) > 0 && !matchCIDRs(h.RemoteCIDRs, net.ParseIP(remoteHost(r.RemoteAddr))) {
+		return false
+	}
+
+	if len(h.ClientCIDRs) > 0 && !matchCIDRs(h.ClientCIDRs, net.ParseIP(clientIP)) {
 		return false
 	}
 
@@ -61,6 +116,17 @@ func (h HttpRequestProperties) Match(r *http.Request) bool {
 		return false
 	}
 
+	if h.UserAgentContains != "" && !strings.Contains(r.UserAgent(), h.UserAgentContains) {
+		return false
+	}
+
+	for name, pattern := range h.Headers {
+		values, ok := r.Header[http.CanonicalHeaderKey(name)]
+		if !ok || !matchAnyValue(pattern, values) {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -76,17 +142,55 @@ func NewAwsHealthCheckHttpRequestProperties(path string) HttpRequestProperties {
 //Logger struct defines a wrapper for a logger object
 type Logger struct {
 	entry            *logrus.Entry
-	sensitiveHeaders []string
+	sensitiveHeaders *nameMatcher
+	sensitiveCookies *nameMatcher
+	allowedHeaders   *nameMatcher
+	maxHeaderLength  int
+	sensitiveQuery   *nameMatcher
+	trustedProxies   []string
+	bufferSize       int
+	sampler          *sampler
+	bufferEntry      *logrus.Entry
 	suppressRequests []HttpRequestProperties
+	extractors       []Propagator
+	injectors        []Propagator
+	output           *sinkFormatter
 }
 
 //LoggerOpts provides configuration options for the Logger type
 type LoggerOpts struct {
 	//JsonFmt: When true, logs will be output in JSON format. Otherwise logs will be in logfmt
 	JsonFmt bool
+	//Format: The format logs will be output in (eg. FormatECS, FormatOTel). Overrides JsonFmt when set
+	Format OutputFormat
+	//Sinks: The destinations logs are written to, each with its own minimum level and format
+	//		 Defaults: stderr in the format selected by JsonFmt
+	Sinks []Sink
+	//Async: When not nil, logs are written to the sinks by a background goroutine instead of the logging goroutine
+	//		 Logger.Close should be called before the service exits so that queued logs are not lost
+	Async *AsyncOpts
 	//SensitiveHeaders: A list of any headers that contain sensitive information and should not be logged
+	//					Names are matched case-insensitively and may be glob patterns (eg. "*-Token", "X-Amz-*")
 	//				    Defaults: Authorization, Csrf
 	SensitiveHeaders []string
+	//SensitiveHeaderPatterns: A list of regular expressions matching the names of any headers that should not be logged
+	//						   Patterns are matched case-insensitively
+	SensitiveHeaderPatterns []string
+	//SensitiveCookies: A list of cookies in the Cookie and Set-Cookie headers whose values should not be logged
+	//					Names are matched case-insensitively and may be glob patterns. The cookie names are still logged.
+	//					Defaults: * (all cookie values are hidden)
+	SensitiveCookies []string
+	//AllowedHeaders: When not nil, only the listed headers are logged and all other headers are omitted
+	//				  Names are matched case-insensitively and may be glob patterns. SensitiveHeaders are still hidden.
+	//				  The number of omitted headers is logged in the request context.
+	AllowedHeaders []string
+	//MaxHeaderLength: The maximum number of bytes of each header value to be logged. Longer values are truncated.
+	//				   Values are not truncated if <= 0
+	MaxHeaderLength int
+	//SensitiveQueryParams: A list of any query parameters that contain sensitive information and should not be logged
+	//						Names are matched case-insensitively and may be glob patterns
+	//						Defaults: token, code, api_key
+	SensitiveQueryParams []string
 	//SuppressRequests: A list of HttpRequestProperties of requests that should not be logged
 	//					Any "Warn" or higher severity logs will still be logged.
 	//					This is useful to prevent info logs from health checks and similar requests from
@@ -94,6 +198,42 @@ type LoggerOpts struct {
 	//					All specified fields in the provided HttpRequestProperties must match for the logs
 	//					to be suppressed. Empty fields will be ignored.
 	SuppressRequests []HttpRequestProperties
+	//TrustedProxies: A list of CIDRs or IP addresses of proxies (eg. load balancers) which are trusted to set the
+	//				  Forwarded, X-Forwarded-For and X-Real-IP headers. Used to determine the client IP of requests.
+	TrustedProxies []string
+	//RequestLogBuffer: When > 0, the Debug and Info logs of each request created by NewRequestLog are buffered, up to
+	//					this number of entries, instead of being printed immediately. The buffered logs are only printed
+	//					by RequestComplete if the request logged an error or responded with a status code >= 500.
+	//					Buffered Debug logs are printed even if the level of the Logger is higher.
+	//					Otherwise, only "Request Complete" is printed. If the buffer is full, the oldest entries are dropped.
+	RequestLogBuffer int
+	//Limiter: When not nil, identical logs are deduplicated and the rate of logs is limited per level
+	Limiter *LimiterOpts
+	//Sampling: When not nil, the Debug and Info logs of requests created by NewRequestLog are sampled per trace
+	//			The decision is propagated by SetHeaders and recorded in the "sampled" field of each log
+	Sampling *SamplingOpts
+	//ExtractFormats: The trace propagation formats that are read from incoming request headers, in order of precedence
+	//				  Defaults: PropagationLegacy, PropagationW3C
+	ExtractFormats []PropagationFormat
+	//InjectFormats: The trace propagation formats that are written to outgoing request headers by SetHeaders
+	//				 Defaults: PropagationLegacy, PropagationW3C
+	InjectFormats []PropagationFormat
+	//Propagators: Any custom Propagators to be used in addition to the ExtractFormats and InjectFormats
+	//			   Custom propagators are read after and written after the built-in formats
+	Propagators []Propagator
+	//SensitiveFields: A list of key paths of fields in log details, context and fields whose values should not be logged
+	//				   Paths are dot separated (eg. "user.ssn") and relative to the details or context. Structs are matched
+	//				   using their JSON field names. "*" matches any key or slice index (eg. "payload.*.token").
+	//				   Paths without a dot match the key at any depth (eg. "password").
+	SensitiveFields []string
+	//FieldRedaction: How the values of SensitiveFields are redacted (RedactMask, RedactHash or RedactDrop)
+	//				  Default: RedactMask
+	FieldRedaction RedactionMode
+	//ScrubDetectors: A list of built-in detectors for sensitive data (eg. emails, tokens) to be scrubbed from all log
+	//				  messages, field values and errors before they are output
+	ScrubDetectors []ScrubDetector
+	//ScrubPatterns: A list of regular expressions matching any other sensitive data to be scrubbed from all logs
+	ScrubPatterns []string
 }
 
 //NewLogger is constructor for a logger object with initial configuration at the service level
@@ -103,7 +243,17 @@ type LoggerOpts struct {
 func NewLogger(serviceName string, opts *LoggerOpts) *Logger {
 	var baseLogger = logrus.New()
 	sensitiveHeaders := []string{"Authorization", "Csrf"}
+	var sensitiveHeaderPatterns []string
+	sensitiveCookies := []string{"*"}
+	var allowedHeaders []string
+	maxHeaderLength := 0
+	sensitiveQueryParams := []string{"token", "code", "api_key"}
 	var suppressRequests []HttpRequestProperties
+	var trustedProxies []string
+	bufferSize := 0
+	extractFormats := []PropagationFormat{PropagationLegacy, PropagationW3C}
+	injectFormats := []PropagationFormat{PropagationLegacy, PropagationW3C}
+	var propagators []Propagator
 
 	if opts != nil {
 		if opts.JsonFmt {
@@ -111,32 +261,137 @@ func NewLogger(serviceName string, opts *LoggerOpts) *Logger {
 		} else {
 			baseLogger.Formatter = &logrus.TextFormatter{}
 		}
+		baseLogger.Formatter = newFormatter(opts.Format, baseLogger.Formatter)
 
 		sensitiveHeaders = append(sensitiveHeaders, opts.SensitiveHeaders...)
+		sensitiveHeaderPatterns = opts.SensitiveHeaderPatterns
+		if opts.SensitiveCookies != nil {
+			sensitiveCookies = opts.SensitiveCookies
+		}
+		allowedHeaders = opts.AllowedHeaders
+		maxHeaderLength = opts.MaxHeaderLength
+		sensitiveQueryParams = append(sensitiveQueryParams, opts.SensitiveQueryParams...)
 		suppressRequests = opts.SuppressRequests
+		trustedProxies = opts.TrustedProxies
+		bufferSize = opts.RequestLogBuffer
+		if opts.ExtractFormats != nil {
+			extractFormats = opts.ExtractFormats
+		}
+		if opts.InjectFormats != nil {
+			injectFormats = opts.InjectFormats
+		}
+		propagators = opts.Propagators
+	}
+
+	var sinkFormatter *sinkFormatter
+	var sinkErrs []error
+	if opts != nil && (len(opts.Sinks) > 0 || opts.Async != nil) {
+		sinks := opts.Sinks
+		if len(sinks) == 0 {
+			sinks = []Sink{{Type: SinkStderr}}
+		}
+		sinkFormatter, sinkErrs = newSinkFormatter(sinks, baseLogger.Formatter)
+		if sinkFormatter != nil {
+			baseLogger.Formatter = sinkFormatter
+			baseLogger.Out = ioutil.Discard
+			if opts.Async != nil {
+				sinkFormatter.async = newAsyncWriter(*opts.Async)
+				baseLogger.ExitFunc = func(code int) {
+					ctx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+					sinkFormatter.flush(ctx)
+					cancel()
+					os.Exit(code)
+				}
+			}
+		}
 	}
 
 	standardFields := logrus.Fields{"service_name": serviceName} //All common fields for logs of a given service
-	contextLogger := &Logger{entry: baseLogger.WithFields(standardFields), sensitiveHeaders: sensitiveHeaders, suppressRequests: suppressRequests}
+	entry := baseLogger.WithFields(standardFields)
+	for _, err := range sinkErrs {
+		entry.Warnf("Failed to open log sink: %v", err)
+	}
+
+	headerMatcher, invalid := newNameMatcher(sensitiveHeaders, sensitiveHeaderPatterns)
+	for _, pattern := range invalid {
+		entry.Warnf("Invalid sensitive header pattern: %s", pattern)
+	}
+	cookieMatcher, _ := newNameMatcher(sensitiveCookies, nil)
+	queryMatcher, _ := newNameMatcher(sensitiveQueryParams, nil)
+	if opts != nil && len(opts.SensitiveFields) > 0 {
+		baseLogger.AddHook(&redactHook{redactor: newFieldRedactor(opts.SensitiveFields, opts.FieldRedaction)})
+	}
+	if opts != nil && (len(opts.ScrubDetectors) > 0 || len(opts.ScrubPatterns) > 0) {
+		scrubber, invalid := newScrubber(opts.ScrubDetectors, opts.ScrubPatterns)
+		for _, pattern := range invalid {
+			entry.Warnf("Invalid scrub pattern: %s", pattern)
+		}
+		baseLogger.AddHook(&scrubHook{scrubber: scrubber})
+	}
+	if opts != nil && opts.Limiter != nil {
+		baseLogger.Formatter = &limitFormatter{Formatter: baseLogger.Formatter, limiter: newLimiter(opts.Limiter)}
+	}
+	var sampler *sampler
+	if opts != nil && opts.Sampling != nil {
+		sampler = newSampler(opts.Sampling)
+	}
+	var allowedMatcher *nameMatcher
+	if allowedHeaders != nil {
+		allowedMatcher, _ = newNameMatcher(allowedHeaders, nil)
+	}
+
+	contextLogger := &Logger{entry: entry, sensitiveHeaders: headerMatcher, sensitiveCookies: cookieMatcher, allowedHeaders: allowedMatcher,
+		maxHeaderLength: maxHeaderLength, sensitiveQuery: queryMatcher, trustedProxies: trustedProxies, bufferSize: bufferSize,
+		bufferEntry: newBufferLogger(baseLogger).WithFields(standardFields), sampler: sampler, suppressRequests: suppressRequests, extractors: newPropagators(extractFormats, propagators),
+		injectors: newPropagators(injectFormats, propagators), output: sinkFormatter}
 	return contextLogger
 }
 
 func (l *Logger) SetLevel(level logLevel) {
+	if logrusLevel, ok := logrusLevel(level); ok {
+		l.entry.Logger.SetLevel(logrusLevel)
+	}
+}
+
+//logrusLevel returns the logrus level corresponding to level
+func logrusLevel(level logLevel) (logrus.Level, bool) {
 	switch level {
 	case Debug:
-		l.entry.Logger.SetLevel(logrus.DebugLevel)
+		return logrus.DebugLevel, true
 	case Info:
-		l.entry.Logger.SetLevel(logrus.InfoLevel)
+		return logrus.InfoLevel, true
 	case Warn:
-		l.entry.Logger.SetLevel(logrus.WarnLevel)
+		return logrus.WarnLevel, true
 	case Error:
-		l.entry.Logger.SetLevel(logrus.ErrorLevel)
+		return logrus.ErrorLevel, true
 	default:
+		return logrus.PanicLevel, false
+	}
+}
+
+//Flush waits until all logs queued by the Async option have been written or ctx is done
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.output == nil {
+		return nil
+	}
+	return l.output.flush(ctx)
+}
+
+//Close writes all logs queued by the Async option and closes the files written by the Logger
+//	Logs printed after Close are written synchronously, except to files.
+func (l *Logger) Close() error {
+	if l.output == nil {
+		return nil
 	}
+	return l.output.close()
 }
 
-func (l *Logger) withFields(fields logutils.Fields) *Logger {
-	return &Logger{entry: l.entry.WithFields(fields.ToMap())}
+//DroppedEntries returns the number of logs which were dropped because the Async queue was full
+func (l *Logger) DroppedEntries() uint64 {
+	if l.output == nil || l.output.async == nil {
+		return 0
+	}
+	return l.output.async.droppedEntries()
 }
 
 //Fatal prints the log with a fatal error message and stops the service instance
@@ -212,26 +467,53 @@ func (l *Logger) Warnf(format string, args ...interface{}) {
 }
 
 type RequestContext struct {
-	Method     string
-	Path       string
-	Headers    map[string][]string
-	PrevSpanID string
+	Method         string
+	Path           string
+	Route          string              `json:",omitempty"`
+	Query          map[string][]string `json:",omitempty"`
+	Headers        map[string][]string
+	OmittedHeaders int    `json:",omitempty"`
+	ClientIP       string `json:",omitempty"`
+	PrevSpanID     string
 }
 
 func (r RequestContext) String() string {
-	return fmt.Sprintf("%s %s prev_span_id: %s headers: %v", r.Method, r.Path, r.PrevSpanID, r.Headers)
+	str := fmt.Sprintf("%s %s", r.Method, r.Path)
+	if r.Route != "" {
+		str += fmt.Sprintf(" route: %s", r.Route)
+	}
+	if len(r.Query) > 0 {
+		str += fmt.Sprintf(" query: %v", r.Query)
+	}
+	if r.ClientIP != "" {
+		str += fmt.Sprintf(" client_ip: %s", r.ClientIP)
+	}
+	str += fmt.Sprintf(" prev_span_id: %s headers: %v", r.PrevSpanID, r.Headers)
+	if r.OmittedHeaders > 0 {
+		str += fmt.Sprintf(" omitted_headers: %d", r.OmittedHeaders)
+	}
+	return str
 }
 
 //Log struct defines a log object of a request
 type Log struct {
-	logger    *Logger
-	traceID   string
-	spanID    string
-	request   RequestContext
-	context   logutils.Fields
-	layer     int
-	suppress  bool
-	hasLogged bool
+	logger      *Logger
+	traceID     string
+	spanID      string
+	traceFlags  string
+	traceState  string
+	request     RequestContext
+	context     logutils.Fields
+	layer       int
+	suppress    bool
+	hasLogged   int32
+	parent      *Log
+	startTime   time.Time
+	response    *responseRecorder
+	requestBody *capturedBody
+	httpRequest *http.Request
+	buffer      *logBuffer
+	sampled     bool
 }
 
 //NewLog is a constructor for a log object
@@ -240,51 +522,93 @@ func (l *Logger) NewLog(traceID string, request RequestContext) *Log {
 		traceID = uuid.New().String()
 	}
 	spanID := uuid.New().String()
-	log := &Log{l, traceID, spanID, request, logutils.Fields{}, 0, false, false}
+	log := &Log{logger: l, traceID: traceID, spanID: spanID, request: request, context: logutils.Fields{}, sampled: true, startTime: time.Now()}
 	return log
 }
 
 //NewRequestLog is a constructor for a log object for a request
 func (l *Logger) NewRequestLog(r *http.Request) *Log {
 	if r == nil {
-		return &Log{logger: l}
+		return &Log{logger: l, traceID: uuid.New().String(), spanID: uuid.New().String(), context: logutils.Fields{},
+			sampled: true, startTime: time.Now()}
 	}
 
-	traceID := r.Header.Get("trace-id")
+	tc, _ := extractTraceContext(r.Header, l.extractors)
+	traceID := tc.TraceID
 	if traceID == "" {
 		traceID = uuid.New().String()
 	}
 
-	prevSpanID := r.Header.Get("span-id")
+	prevSpanID := tc.SpanID
 	spanID := uuid.New().String()
 
 	method := r.Method
 	path := r.URL.Path
 
-	headers := make(map[string][]string)
-	for key, value := range r.Header {
-		var logValue []string
-		//do not log sensitive information
-		if logutils.ContainsString(l.sensitiveHeaders, key) {
-			logValue = append(logValue, "---")
-		} else {
-			logValue = value
-		}
-		headers[key] = logValue
+	headers, omittedHeaders := l.redactHeaders(r.Header)
+	query := l.redactQuery(r.URL.RawQuery)
+
+	clientIP := l.clientIP(r)
+
+	request := RequestContext{Method: method, Path: path, Query: query, Headers: headers, OmittedHeaders: omittedHeaders,
+		ClientIP: clientIP, PrevSpanID: prevSpanID}
+
+	suppress := l.suppressRequest(r, "", clientIP)
+
+	log := &Log{logger: l, traceID: traceID, spanID: spanID, traceFlags: tc.TraceFlags, traceState: tc.TraceState, request: request,
+		context: logutils.Fields{}, suppress: suppress, sampled: true, startTime: time.Now(), httpRequest: r}
+	log.applySampling(tc.TraceFlags)
+	if l.bufferSize > 0 {
+		log.buffer = newLogBuffer(l.bufferSize)
+	}
+	return log
+}
+
+//TraceContext returns the trace context to be propagated to other services
+func (l *Log) TraceContext() TraceContext {
+	if l == nil {
+		return TraceContext{}
 	}
 
-	request := RequestContext{Method: method, Path: path, Headers: headers, PrevSpanID: prevSpanID}
+	return TraceContext{TraceID: l.traceID, SpanID: l.spanID, TraceFlags: l.traceFlags, TraceState: l.traceState}
+}
 
-	suppress := false
-	for _, props := range l.suppressRequests {
-		if props.Match(r) {
-			suppress = true
-			break
-		}
+//ClientIP returns the IP address of the client which sent the request
+//	The address is read from the forwarding headers when the request was received from one of the TrustedProxies
+func (l *Log) ClientIP() string {
+	if l == nil {
+		return ""
 	}
+	return l.request.ClientIP
+}
 
-	log := &Log{l, traceID, spanID, request, logutils.Fields{}, 0, suppress, false}
-	return log
+//SetRoute sets the matched route template of the request (eg. "/users/{id}")
+//	Routers should call this when the request has been matched so that logs can be aggregated by route instead of path.
+//	The SuppressRequests and Sampling of the Logger are evaluated again using the route.
+func (l *Log) SetRoute(route string) {
+	if l == nil {
+		return
+	}
+
+	l.request.Route = route
+	if l.logger != nil && l.httpRequest != nil {
+		l.suppress = l.logger.suppressRequest(l.httpRequest, route, l.request.ClientIP)
+		tc, _ := extractTraceContext(l.httpRequest.Header, l.logger.extractors)
+		l.applySampling(tc.TraceFlags)
+	}
+}
+
+//WrapResponseWriter wraps the provided http.ResponseWriter to record the status code and size of the response
+//	The returned http.ResponseWriter should be used to write the response in place of w so that RequestComplete
+//	prints the real status code and response size
+func (l *Log) WrapResponseWriter(w http.ResponseWriter) http.ResponseWriter {
+	if l == nil || w == nil {
+		return w
+	}
+
+	rec := &responseRecorder{w: w}
+	l.response = rec
+	return wrapResponseWriter(w, rec)
 }
 
 func (l *Log) resetLayer() {
@@ -302,25 +626,38 @@ func (l *Log) getRequestFields() logutils.Fields {
 		return logutils.Fields{}
 	}
 
-	l.hasLogged = true
 	fields := logutils.Fields{"trace_id": l.traceID, "span_id": l.spanID, "function_name": getLogPrevFuncName(l.layer)}
+	if l.parent != nil {
+		fields["parent_span_id"] = l.parent.spanID
+	}
 	if l.suppress {
 		fields["suppress"] = true
 	}
+	if l.logger != nil && l.logger.sampler != nil {
+		fields["sampled"] = l.root().sampled
+	}
 	l.resetLayer()
 
 	return fields
 }
 
 //SetHeaders sets the trace and span id headers for a request to another service
+//	The headers are written using each of the InjectFormats and Propagators configured on the Logger
 //	This function should always be called when making a request to another rokwire service
 func (l *Log) SetHeaders(r *http.Request) {
-	if l == nil {
+	if l == nil || r == nil {
 		return
 	}
 
-	r.Header.Set("trace-id", l.traceID)
-	r.Header.Set("span-id", l.spanID)
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	injectors := []Propagator{LegacyPropagator{}}
+	if l.logger != nil {
+		injectors = l.logger.injectors
+	}
+	injectTraceContext(r.Header, injectors, l.TraceContext())
 }
 
 //LogData logs and returns a data message at the designated level
@@ -517,64 +854,64 @@ func (l *Log) HttpResponseErrorAction(action logutils.MessageActionType, dataTyp
 
 //Info prints the log at info level with given message
 func (l *Log) Info(message string) {
-	if l == nil || l.logger == nil || l.suppress {
+	if l == nil || l.logger == nil || (l.suppress && !l.buffered()) {
 		return
 	}
 
 	requestFields := l.getRequestFields()
-	l.logger.withFields(requestFields).Info(message)
+	l.output(logrus.InfoLevel, message, requestFields)
 }
 
 //InfoWithDetails prints the log at info level with given fields and message
 func (l *Log) InfoWithDetails(message string, details logutils.Fields) {
-	if l == nil || l.logger == nil || l.suppress {
+	if l == nil || l.logger == nil || (l.suppress && !l.buffered()) {
 		return
 	}
 
 	requestFields := l.getRequestFields()
 	requestFields["details"] = details
-	l.logger.withFields(requestFields).Info(message)
+	l.output(logrus.InfoLevel, message, requestFields)
 }
 
 //Infof prints the log at info level with given formatted string
 func (l *Log) Infof(format string, args ...interface{}) {
-	if l == nil || l.logger == nil || l.suppress {
+	if l == nil || l.logger == nil || (l.suppress && !l.buffered()) {
 		return
 	}
 
 	requestFields := l.getRequestFields()
-	l.logger.withFields(requestFields).Infof(format, args...)
+	l.output(logrus.InfoLevel, fmt.Sprintf(format, args...), requestFields)
 }
 
 //Debug prints the log at debug level with given message
 func (l *Log) Debug(message string) {
-	if l == nil || l.logger == nil || l.suppress {
+	if l == nil || l.logger == nil || (l.suppress && !l.buffered()) {
 		return
 	}
 
 	requestFields := l.getRequestFields()
-	l.logger.withFields(requestFields).Debug(message)
+	l.output(logrus.DebugLevel, message, requestFields)
 }
 
 //DebugWithDetails prints the log at debug level with given fields and message
 func (l *Log) DebugWithDetails(message string, details logutils.Fields) {
-	if l == nil || l.logger == nil || l.suppress {
+	if l == nil || l.logger == nil || (l.suppress && !l.buffered()) {
 		return
 	}
 
 	requestFields := l.getRequestFields()
 	requestFields["details"] = details
-	l.logger.withFields(requestFields).Debug(message)
+	l.output(logrus.DebugLevel, message, requestFields)
 }
 
 //Debugf prints the log at debug level with given formatted string
 func (l *Log) Debugf(format string, args ...interface{}) {
-	if l == nil || l.logger == nil || l.suppress {
+	if l == nil || l.logger == nil || (l.suppress && !l.buffered()) {
 		return
 	}
 
 	requestFields := l.getRequestFields()
-	l.logger.withFields(requestFields).Debugf(format, args...)
+	l.output(logrus.DebugLevel, fmt.Sprintf(format, args...), requestFields)
 }
 
 //Warn prints the log at warn level with given message
@@ -584,7 +921,7 @@ func (l *Log) Warn(message string) {
 	}
 
 	requestFields := l.getRequestFields()
-	l.logger.withFields(requestFields).Warn(message)
+	l.output(logrus.WarnLevel, message, requestFields)
 }
 
 //WarnWithDetails prints the log at warn level with given details and message
@@ -595,7 +932,7 @@ func (l *Log) WarnWithDetails(message string, details logutils.Fields) {
 
 	requestFields := l.getRequestFields()
 	requestFields["details"] = details
-	l.logger.withFields(requestFields).Warn(message)
+	l.output(logrus.WarnLevel, message, requestFields)
 }
 
 //Warnf prints the log at warn level with given formatted string
@@ -605,7 +942,7 @@ func (l *Log) Warnf(format string, args ...interface{}) {
 	}
 
 	requestFields := l.getRequestFields()
-	l.logger.withFields(requestFields).Warnf(format, args...)
+	l.output(logrus.WarnLevel, fmt.Sprintf(format, args...), requestFields)
 }
 
 //WarnError prints the log at warn level with given message and error
@@ -620,7 +957,7 @@ func (l *Log) WarnError(message string, err error) string {
 	if err != nil {
 		requestFields["error"] = err.Error()
 	}
-	l.logger.withFields(requestFields).Warn(message)
+	l.output(logrus.WarnLevel, message, requestFields)
 	return msg
 }
 
@@ -636,7 +973,7 @@ func (l *Log) LogError(message string, err error) string {
 	if err != nil {
 		requestFields["error"] = err.Error()
 	}
-	l.logger.withFields(requestFields).Error(message)
+	l.output(logrus.ErrorLevel, message, requestFields)
 	return msg
 }
 
@@ -648,7 +985,7 @@ func (l *Log) Error(message string) {
 	}
 
 	requestFields := l.getRequestFields()
-	l.logger.withFields(requestFields).Error(message)
+	l.output(logrus.ErrorLevel, message, requestFields)
 }
 
 //ErrorWithDetails prints the log at error level with given details and message
@@ -659,7 +996,7 @@ func (l *Log) ErrorWithDetails(message string, details logutils.Fields) {
 
 	requestFields := l.getRequestFields()
 	requestFields["details"] = details
-	l.logger.withFields(requestFields).Error(message)
+	l.output(logrus.ErrorLevel, message, requestFields)
 }
 
 //Errorf prints the log at error level with given formatted string
@@ -670,7 +1007,7 @@ func (l *Log) Errorf(format string, args ...interface{}) {
 	}
 
 	requestFields := l.getRequestFields()
-	l.logger.withFields(requestFields).Errorf(format, args...)
+	l.output(logrus.ErrorLevel, fmt.Sprintf(format, args...), requestFields)
 }
 
 //RequestSuccess sets "Success" as the HTTP response, sets standard headers, and stores the message
@@ -807,39 +1144,108 @@ func (l *Log) AddContext(fieldName string, value interface{}) error {
 
 //SetContext sets the provided context key to the provided value
 func (l *Log) SetContext(fieldName string, value interface{}) {
+	if l == nil {
+		return
+	}
+
+	if l.context == nil {
+		l.context = logutils.Fields{}
+	}
 	l.context[fieldName] = value
 }
 
 //RequestReceived prints the request context of a log object
 func (l *Log) RequestReceived() {
-	if l == nil || l.logger == nil || l.suppress {
+	if l == nil || l.logger == nil || (l.suppress && !l.buffered()) {
 		return
 	}
 
 	fields := l.getRequestFields()
 	fields["request"] = l.request
-	l.logger.InfoWithFields("Request Received", fields)
+	l.output(logrus.InfoLevel, "Request Received", fields)
 }
 
-//RequestComplete prints the context of a log object
+//RequestComplete prints the context of a log object along with the duration of the request
+//	The response status code and size are also printed if the response writer was wrapped using WrapResponseWriter.
+//	If RequestLogBuffer is set on the Logger, the buffered logs of the request are printed first if the request logged
+//	an error or responded with a status code >= 500. Otherwise, the request is printed with this log instead.
+//	If the request was not sampled, this is only printed if the request logged a warning or error or responded with a
+//	status code >= 500.
 func (l *Log) RequestComplete() {
 	if l == nil || l.logger == nil {
 		return
 	}
 
-	hasLogged := l.hasLogged
+	statusCode := l.statusCode()
+	bufferDropped, bufferDiscarded := l.flushBuffer(statusCode)
+
+	hasLogged := l.logged()
 	fields := l.getRequestFields()
 
-	if l.suppress {
-		if hasLogged {
+	if l.suppress || !l.sampled {
+		//Requests which were not sampled are still printed if they failed so that errors are not lost
+		failed := !l.suppress && statusCode >= http.StatusInternalServerError
+		if hasLogged || failed {
 			fields["request"] = l.request
 		} else {
 			return
 		}
+	} else if bufferDiscarded {
+		//"Request Received" was discarded with the buffer, so the request is printed here instead
+		fields["request"] = l.request
+	}
+
+	if l.request.Route != "" {
+		fields["route"] = l.request.Route
+	}
+	if !l.startTime.IsZero() {
+		fields["duration_ms"] = float64(time.Since(l.startTime)) / float64(time.Millisecond)
+	}
+	if statusCode != 0 {
+		fields["status_code"] = statusCode
+	}
+	if l.response != nil {
+		fields["response_size"] = l.response.size
+		if l.response.body != nil {
+			fields["response_body"] = l.response.body.logValue()
+			if l.response.body.truncated {
+				fields["response_body_truncated"] = true
+			}
+		}
+	}
+
+	if l.requestBody != nil {
+		fields["request_body"] = l.requestBody.logValue()
+		if l.requestBody.truncated {
+			fields["request_body_truncated"] = true
+		}
+	}
+
+	if bufferDropped > 0 {
+		fields["buffer_dropped"] = bufferDropped
 	}
 
 	fields["context"] = l.context
-	l.logger.InfoWithFields("Request Complete", fields)
+	l.write(logrus.InfoLevel, "Request Complete", fields)
+}
+
+//statusCode returns the response status code of the request, or 0 if it is unknown
+func (l *Log) statusCode() int {
+	if l.response == nil {
+		status, _ := l.context["status_code"].(int)
+		return status
+	}
+
+	if l.response.hijacked {
+		return 0
+	}
+
+	status := l.response.status
+	//net/http responds with 200 when the handler returns without writing, but not when it panics
+	if status == 0 && l.context["panic"] == nil {
+		status = http.StatusOK
+	}
+	return status
 }
 
 //getLogPrevFuncName - fetches the calling function name when logging
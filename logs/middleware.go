@@ -0,0 +1,89 @@
+package logs
+
+import (
+	"net/http"
+)
+
+//MiddlewareOpts provides configuration options for Middleware
+type MiddlewareOpts struct {
+	//SkipRequestReceived: When true, the "Request Received" log will not be printed for each request
+	//					   The request details are still printed with "Request Complete" when the request is not suppressed
+	SkipRequestReceived bool
+	//Recovery: When not nil, panics in the handler are recovered and logged with their stack trace and a 500 response is written
+	//			The panic is logged before "Request Complete"
+	Recovery *RecoveryOpts
+	//BodyCapture: When not nil, request and/or response bodies are captured and printed with "Request Complete"
+	BodyCapture *BodyCaptureOpts
+	//RouteFunc: A function which returns the matched route template of a request (eg. "/users/{id}")
+	//			 This should be used when the middleware runs after the router has matched the request
+	//			 Otherwise, the handler should call Log.SetRoute once the route is known
+	RouteFunc func(r *http.Request) string
+}
+
+//Middleware returns standard net/http middleware which performs request logging
+//	For each request, a request Log is created and stored in the request context, the "Request Received" log is printed,
+//	and the "Request Complete" log is printed after the handler returns, including when the handler panics.
+//	The response writer is wrapped so that the status code, response size and duration of each request are logged.
+//	Handlers can access the request Log using FromContext(r.Context()).
+//	Params:
+//		logger: The Logger to be used to create request logs
+//		opts: Configuration options for the middleware (nil for defaults)
+func Middleware(logger *Logger, opts *MiddlewareOpts) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &MiddlewareOpts{}
+	}
+
+	var capture *bodyCapture
+	if opts.BodyCapture != nil {
+		capture = newBodyCapture(opts.BodyCapture)
+	}
+
+	return func(next http.Handler) http.Handler {
+		if logger == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log, r := logger.NewRequestLogWithContext(r)
+			w = log.WrapResponseWriter(w)
+			log.captureBodies(r, capture)
+			if opts.RouteFunc != nil {
+				log.SetRoute(opts.RouteFunc(r))
+			}
+			if !opts.SkipRequestReceived {
+				log.RequestReceived()
+			}
+
+			completed := false
+			defer func() {
+				if completed {
+					log.RequestComplete()
+					return
+				}
+
+				if opts.Recovery == nil {
+					//The panic is not recovered so that it continues to propagate with its original stack
+					log.SetContext("panic", true)
+					log.RequestComplete()
+					return
+				}
+
+				rec := recover()
+				if rec == nil {
+					//The handler exited using runtime.Goexit
+					log.RequestComplete()
+					return
+				}
+
+				log.handlePanic(w, rec, opts.Recovery)
+				log.RequestComplete()
+				if rec == http.ErrAbortHandler || opts.Recovery.Repanic {
+					panic(rec)
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+			completed = true
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+//otelScopeName is the instrumentation scope of the log records
+const otelScopeName = "github.com/rokmetro/logging-library/logs"
+
+//otelFormatter is a logrus formatter which formats entries as OpenTelemetry OTLP/JSON logs
+//	Each entry is formatted as an export request containing a single log record, so the output can be ingested by an
+//	OpenTelemetry collector (eg. using the otlpjsonfile receiver). service_name is added to the resource, and trace_id
+//	and span_id are converted to the hex format of W3C Trace Context. Other fields are added as attributes.
+type otelFormatter struct{}
+
+//otelAnyValue is an OTLP AnyValue
+type otelAnyValue map[string]interface{}
+
+//otelKeyValue is an OTLP KeyValue
+type otelKeyValue struct {
+	Key   string       `json:"key"`
+	Value otelAnyValue `json:"value"`
+}
+
+//Format formats the entry as an OTLP/JSON export request
+func (f *otelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	severityNumber, severityText := otelSeverity(entry.Level)
+	timestamp := strconv.FormatInt(entry.Time.UnixNano(), 10)
+	record := map[string]interface{}{
+		"timeUnixNano":         timestamp,
+		"observedTimeUnixNano": timestamp,
+		"severityNumber":       severityNumber,
+		"severityText":         severityText,
+		"body":                 otelAnyValue{"stringValue": entry.Message},
+	}
+
+	var resource []otelKeyValue
+	var attributes []otelKeyValue
+	for key, value := range entry.Data {
+		switch key {
+		case "service_name":
+			resource = append(resource, otelKeyValue{Key: "service.name", Value: otelValue(value)})
+		case "trace_id":
+			record["traceId"] = w3cTraceID(fmt.Sprint(value))
+		case "span_id":
+			record["spanId"] = w3cSpanID(fmt.Sprint(value))
+		case "function_name":
+			attributes = append(attributes, otelKeyValue{Key: "code.function", Value: otelValue(value)})
+		default:
+			attributes = append(attributes, otelKeyValue{Key: key, Value: otelValue(value)})
+		}
+	}
+
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+	if len(attributes) > 0 {
+		record["attributes"] = attributes
+	}
+
+	request := map[string]interface{}{
+		"resourceLogs": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{"attributes": resource},
+				"scopeLogs": []interface{}{
+					map[string]interface{}{
+						"scope":      map[string]interface{}{"name": otelScopeName},
+						"logRecords": []interface{}{record},
+					},
+				},
+			},
+		},
+	}
+
+	serialized, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to OTLP JSON, %v", err)
+	}
+	return append(serialized, '\n'), nil
+}
+
+//otelSeverity returns the OpenTelemetry severity number and text for level
+//	The severity text is the name of the corresponding logLevel.
+func otelSeverity(level logrus.Level) (int, string) {
+	switch level {
+	case logrus.TraceLevel:
+		return 1, "Trace"
+	case logrus.DebugLevel:
+		return 5, string(Debug)
+	case logrus.InfoLevel:
+		return 9, string(Info)
+	case logrus.WarnLevel:
+		return 13, string(Warn)
+	case logrus.ErrorLevel:
+		return 17, string(Error)
+	case logrus.FatalLevel:
+		return 21, "Fatal"
+	default:
+		return 24, "Panic"
+	}
+}
+
+//otelValue converts value to an OTLP AnyValue
+//	Values which are not primitives, maps or slices are converted using their JSON representation.
+func otelValue(value interface{}) otelAnyValue {
+	switch v := value.(type) {
+	case nil:
+		return otelAnyValue{}
+	case string:
+		return otelAnyValue{"stringValue": v}
+	case bool:
+		return otelAnyValue{"boolValue": v}
+	case error:
+		return otelAnyValue{"stringValue": v.Error()}
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return otelAnyValue{"intValue": strconv.FormatInt(rv.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return otelAnyValue{"intValue": strconv.FormatUint(rv.Uint(), 10)}
+	case reflect.Float32, reflect.Float64:
+		return otelAnyValue{"doubleValue": rv.Float()}
+	case reflect.String:
+		return otelAnyValue{"stringValue": rv.String()}
+	case reflect.Bool:
+		return otelAnyValue{"boolValue": rv.Bool()}
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return otelAnyValue{"stringValue": fmt.Sprintf("%s", value)}
+		}
+		values := make([]otelAnyValue, rv.Len())
+		for i := range values {
+			values[i] = otelValue(rv.Index(i).Interface())
+		}
+		return otelAnyValue{"arrayValue": map[string]interface{}{"values": values}}
+	case reflect.Map:
+		values := make([]otelKeyValue, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			values = append(values, otelKeyValue{Key: fmt.Sprint(iter.Key().Interface()), Value: otelValue(iter.Value().Interface())})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			return values[i].Key < values[j].Key
+		})
+		return otelAnyValue{"kvlistValue": map[string]interface{}{"values": values}}
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return otelAnyValue{}
+		}
+	}
+
+	//Structs and other values are converted using their JSON representation
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return otelAnyValue{"stringValue": fmt.Sprint(value)}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(serialized, &decoded); err != nil {
+		return otelAnyValue{"stringValue": string(serialized)}
+	}
+	if decoded == nil {
+		return otelAnyValue{}
+	}
+	return otelValue(decoded)
+}
@@ -0,0 +1,121 @@
+package logs
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+//RecoveryOpts provides configuration options for panic recovery
+type RecoveryOpts struct {
+	//Repanic: When true, the panic is raised again after it has been logged and the response has been written
+	//		   This is useful when another recovery mechanism (eg. a crash reporter) should also handle the panic
+	Repanic bool
+	//ShowDetails: When true, the panic value is included in the HTTP response body
+	ShowDetails bool
+}
+
+//Recover recovers from a panic, prints the panic value and stack trace at error level, and responds with a 500
+//	This function must be deferred directly (eg. defer log.Recover(w, nil)) to be able to recover the panic
+//	Params:
+//		w: The http response writer for the active request (nil if no response should be written)
+//		opts: Configuration options for the recovery (nil for defaults)
+func (l *Log) Recover(w http.ResponseWriter, opts *RecoveryOpts) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+
+	l.handlePanic(w, rec, opts)
+	if opts != nil && opts.Repanic {
+		panic(rec)
+	}
+}
+
+//RecoveryMiddleware returns standard net/http middleware which recovers from panics in the handler
+//	The panic is logged using the Log stored in the request context, so this middleware should be used inside
+//	Middleware or with requests created by NewRequestLogWithContext.
+//	To log the panic before "Request Complete", use MiddlewareOpts.Recovery instead.
+func RecoveryMiddleware(opts *RecoveryOpts) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer FromContext(r.Context()).Recover(w, opts)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+//handlePanic prints the recovered panic value and stack trace and writes the error response
+//	http.ErrAbortHandler is not logged and no response is written since it is used to intentionally abort a response,
+//	but it is still recorded in the context so the aborted request is not reported as successful
+func (l *Log) handlePanic(w http.ResponseWriter, rec interface{}, opts *RecoveryOpts) {
+	if rec == http.ErrAbortHandler {
+		l.SetContext("panic", fmt.Sprint(rec))
+		return
+	}
+
+	if opts == nil {
+		opts = &RecoveryOpts{}
+	}
+
+	stack := string(debug.Stack())
+	panicMsg := fmt.Sprint(rec)
+	code := http.StatusInternalServerError
+	l.SetContext("panic", panicMsg)
+	l.SetContext("status_code", code)
+
+	if l != nil && l.logger != nil {
+		fields := l.getRequestFields()
+		fields["function_name"] = panicFuncName()
+		fields["panic"] = panicMsg
+		fields["stack"] = stack
+		fields["request"] = l.request
+		fields["context"] = l.context
+		l.output(logrus.ErrorLevel, "Panic Recovered", fields)
+	}
+
+	if w == nil {
+		return
+	}
+	//The status code cannot be changed once the response has been started
+	if l != nil && l.response != nil && (l.response.status != 0 || l.response.hijacked) {
+		return
+	}
+
+	message := fmt.Sprintf("%d - %s", code, http.StatusText(code))
+	if opts.ShowDetails {
+		message = fmt.Sprintf("%s: %s", message, panicMsg)
+	}
+
+	response := NewErrorHttpResponse(message, code)
+	for key, values := range response.Headers {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(response.ResponseCode)
+	w.Write(response.Body)
+}
+
+//panicFuncName returns the name of the function which caused the current panic
+func panicFuncName() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame.Function
+		}
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			return ""
+		}
+	}
+}
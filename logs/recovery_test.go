@@ -0,0 +1,62 @@
+package logs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		panicValue interface{}
+		wantStatus interface{}
+		wantPanic  bool
+		wantLogged bool
+	}{
+		{name: "panic", panicValue: "boom", wantStatus: float64(http.StatusInternalServerError), wantLogged: true},
+		{name: "abort handler", panicValue: http.ErrAbortHandler, wantStatus: nil, wantPanic: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newTestLogger(LoggerOpts{})
+			handler := Middleware(logger, &MiddlewareOpts{Recovery: &RecoveryOpts{}})(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					panic(tt.panicValue)
+				}))
+
+			repanicked := func() (repanicked bool) {
+				defer func() { repanicked = recover() != nil }()
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+				return false
+			}()
+			if repanicked != tt.wantPanic {
+				t.Errorf("repanicked = %v, want %v", repanicked, tt.wantPanic)
+			}
+
+			var complete map[string]interface{}
+			logged := false
+			for _, entry := range buf.entries(t) {
+				switch entry["msg"] {
+				case "Panic Recovered":
+					logged = true
+				case "Request Complete":
+					complete = entry
+				}
+			}
+			if logged != tt.wantLogged {
+				t.Errorf("panic logged = %v, want %v", logged, tt.wantLogged)
+			}
+			if complete == nil {
+				t.Fatal("Request Complete was not logged")
+			}
+			if got := complete["status_code"]; got != tt.wantStatus {
+				t.Errorf("status_code = %v, want %v", got, tt.wantStatus)
+			}
+			if context, _ := complete["context"].(map[string]interface{}); context["panic"] == nil {
+				t.Errorf("context = %v, want panic", complete["context"])
+			}
+		})
+	}
+}
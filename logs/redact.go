@@ -0,0 +1,338 @@
+package logs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maxRedactDepth = 32
+
+//fieldRedactor redacts the values of sensitive fields identified by key paths
+type fieldRedactor struct {
+	paths [][]string
+	mode  RedactionMode
+}
+
+//newFieldRedactor creates a fieldRedactor
+//	paths: Dot separated key paths (eg. "user.ssn", "payload.*.token"). Paths without a dot match the key at any depth.
+//	mode: The redaction mode (empty for RedactMask)
+func newFieldRedactor(paths []string, mode RedactionMode) *fieldRedactor {
+	if mode == "" {
+		mode = RedactMask
+	}
+
+	r := &fieldRedactor{mode: mode}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		r.paths = append(r.paths, strings.Split(strings.ToLower(path), "."))
+	}
+	return r
+}
+
+//match returns true if the provided key path matches any of the sensitive paths
+func (r *fieldRedactor) match(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	for _, sensitive := range r.paths {
+		if len(sensitive) == 1 {
+			if matchSegment(sensitive[0], path[len(path)-1]) {
+				return true
+			}
+			continue
+		}
+
+		if len(sensitive) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i, segment := range sensitive {
+			if !matchSegment(segment, path[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegment(pattern string, key string) bool {
+	return pattern == "*" || pattern == strings.ToLower(key)
+}
+
+//redactedValue returns the value to be logged in place of a sensitive value
+func (r *fieldRedactor) redactedValue(value interface{}) interface{} {
+	if r.mode == RedactHash {
+		sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	return redactedValue
+}
+
+//redact returns a copy of value with all sensitive fields redacted
+//	Maps, slices, pointers and structs (using their JSON field names) are walked recursively.
+//	Values which do not contain any sensitive fields are returned unchanged and the second return value is false.
+func (r *fieldRedactor) redact(value interface{}, path []string) (interface{}, bool) {
+	if value == nil || len(path) > maxRedactDepth {
+		return value, false
+	}
+	return r.redactValue(reflect.ValueOf(value), path)
+}
+
+func (r *fieldRedactor) redactValue(v reflect.Value, path []string) (interface{}, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v.Interface(), false
+		}
+		if redacted, changed := r.redact(v.Elem().Interface(), path); changed {
+			return redacted, true
+		}
+		return v.Interface(), false
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface(), false
+		}
+		return r.redactMap(v, path)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), false
+		}
+		return r.redactSlice(v, path)
+	case reflect.Struct:
+		if request, ok := v.Interface().(RequestContext); ok {
+			return r.redactRequest(request, path)
+		}
+		return r.redactStruct(v, path)
+	default:
+		return v.Interface(), false
+	}
+}
+
+func (r *fieldRedactor) redactMap(v reflect.Value, path []string) (interface{}, bool) {
+	entries := make(map[string]interface{}, v.Len())
+	changed := false
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		value, drop, redacted := r.redactField(key, iter.Value().Interface(), path)
+		changed = changed || redacted
+		if !drop {
+			entries[key] = value
+		}
+	}
+
+	if !changed {
+		return v.Interface(), false
+	}
+
+	//Preserve map types which can hold any value (eg. logutils.Fields)
+	if v.Type().Elem().Kind() == reflect.Interface {
+		out := reflect.MakeMapWithSize(v.Type(), len(entries))
+		for key, value := range entries {
+			if value == nil {
+				out.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), reflect.Zero(v.Type().Elem()))
+				continue
+			}
+			out.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), reflect.ValueOf(value))
+		}
+		return out.Interface(), true
+	}
+	return entries, true
+}
+
+func (r *fieldRedactor) redactSlice(v reflect.Value, path []string) (interface{}, bool) {
+	values := make([]interface{}, 0, v.Len())
+	changed := false
+
+	for i := 0; i < v.Len(); i++ {
+		value, drop, redacted := r.redactField(strconv.Itoa(i), v.Index(i).Interface(), path)
+		changed = changed || redacted
+		if !drop {
+			values = append(values, value)
+		}
+	}
+
+	if !changed {
+		return v.Interface(), false
+	}
+	return values, true
+}
+
+func (r *fieldRedactor) redactStruct(v reflect.Value, path []string) (interface{}, bool) {
+	fields := make(map[string]interface{}, v.NumField())
+	changed := false
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		value, drop, redacted := r.redactField(name, v.Field(i).Interface(), path)
+		changed = changed || redacted
+		if !drop {
+			fields[name] = value
+		}
+	}
+
+	if !changed {
+		return v.Interface(), false
+	}
+	return fields, true
+}
+
+//redactRequest redacts the fields of a RequestContext without changing its type
+//	The type is kept so that the request can still be formatted by the Logger (eg. as ECS fields).
+func (r *fieldRedactor) redactRequest(request RequestContext, path []string) (interface{}, bool) {
+	changed := false
+	redactString := func(name string, value *string) {
+		if !r.match(append(path[:len(path):len(path)], name)) {
+			return
+		}
+		changed = true
+		if r.mode == RedactDrop {
+			*value = ""
+			return
+		}
+		*value = fmt.Sprint(r.redactedValue(*value))
+	}
+
+	redactString("Method", &request.Method)
+	redactString("Path", &request.Path)
+	redactString("Route", &request.Route)
+	redactString("ClientIP", &request.ClientIP)
+	redactString("PrevSpanID", &request.PrevSpanID)
+
+	if headers, redacted := r.redactStringsMap(request.Headers, append(path[:len(path):len(path)], "Headers")); redacted {
+		request.Headers = headers
+		changed = true
+	}
+	if query, redacted := r.redactStringsMap(request.Query, append(path[:len(path):len(path)], "Query")); redacted {
+		request.Query = query
+		changed = true
+	}
+
+	return request, changed
+}
+
+//redactStringsMap returns a copy of m with the values of sensitive keys redacted
+//	If the map itself is sensitive, all of its values are redacted.
+func (r *fieldRedactor) redactStringsMap(m map[string][]string, path []string) (map[string][]string, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	all := r.match(path)
+	var redacted map[string][]string
+	for key, values := range m {
+		if !all && !r.match(append(path[:len(path):len(path)], key)) {
+			continue
+		}
+
+		if redacted == nil {
+			redacted = make(map[string][]string, len(m))
+			for k, v := range m {
+				redacted[k] = v
+			}
+		}
+		if r.mode == RedactDrop {
+			delete(redacted, key)
+			continue
+		}
+		redacted[key] = []string{fmt.Sprint(r.redactedValue(strings.Join(values, ",")))}
+	}
+
+	if redacted == nil {
+		return m, false
+	}
+	return redacted, true
+}
+
+//redactField redacts a single field of a map, slice or struct
+//	Returns the value to be logged, whether the field should be dropped, and whether the field was changed
+func (r *fieldRedactor) redactField(key string, value interface{}, path []string) (interface{}, bool, bool) {
+	fieldPath := append(path[:len(path):len(path)], key)
+	if r.match(fieldPath) {
+		if r.mode == RedactDrop {
+			return nil, true, true
+		}
+		return r.redactedValue(value), false, true
+	}
+
+	redacted, changed := r.redact(value, fieldPath)
+	return redacted, false, changed
+}
+
+//redactHook is a logrus hook which redacts sensitive fields of each entry before it is formatted
+type redactHook struct {
+	redactor *fieldRedactor
+}
+
+//Levels returns the levels the hook is fired for
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+//Fire redacts the sensitive fields of the entry
+//	Paths are relative to the details, context and body fields, and to the entry itself for all other fields.
+//	Nested values are copied so that the caller's data is not modified.
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	for key, value := range entry.Data {
+		if isStandardField(key) {
+			continue
+		}
+
+		if isContainerField(key) {
+			if redacted, changed := h.redactor.redact(value, nil); changed {
+				entry.Data[key] = redacted
+			}
+			continue
+		}
+
+		redacted, drop, changed := h.redactor.redactField(key, value, nil)
+		if drop {
+			delete(entry.Data, key)
+		} else if changed {
+			entry.Data[key] = redacted
+		}
+	}
+	return nil
+}
+
+//isContainerField returns true if key is a field whose value contains the data provided by the caller
+//	Sensitive field paths are relative to the values of these fields
+func isContainerField(key string) bool {
+	switch key {
+	case "details", "context", "request_body", "response_body":
+		return true
+	default:
+		return false
+	}
+}
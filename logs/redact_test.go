@@ -0,0 +1,133 @@
+package logs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rokmetro/logging-library/logutils"
+)
+
+func TestFieldRedactorMatch(t *testing.T) {
+	r := newFieldRedactor([]string{"password", "user.SSN", "payload.*.token", ""}, "")
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "password", want: true},
+		{path: "user.password", want: true},
+		{path: "a.b.c.Password", want: true},
+		{path: "password.value", want: false},
+		{path: "user.ssn", want: true},
+		{path: "USER.ssn", want: true},
+		{path: "ssn", want: false},
+		{path: "account.user.ssn", want: false},
+		{path: "payload.0.token", want: true},
+		{path: "payload.items.token", want: true},
+		{path: "payload.token", want: false},
+		{path: "payload.0.1.token", want: false},
+		{path: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var path []string
+			if tt.path != "" {
+				path = strings.Split(tt.path, ".")
+			}
+			if got := r.match(path); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldRedactorModes(t *testing.T) {
+	type user struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+		Secret   string `json:"-"`
+		Token    string
+	}
+	hashed := newFieldRedactor(nil, RedactHash).redactedValue("hunter2")
+
+	tests := []struct {
+		name  string
+		mode  RedactionMode
+		paths []string
+		value interface{}
+		want  interface{}
+	}{
+		{name: "mask map", mode: RedactMask, paths: []string{"password"},
+			value: logutils.Fields{"name": "jane", "password": "hunter2"},
+			want:  logutils.Fields{"name": "jane", "password": redactedValue}},
+		{name: "hash map", mode: RedactHash, paths: []string{"password"},
+			value: logutils.Fields{"name": "jane", "password": "hunter2"},
+			want:  logutils.Fields{"name": "jane", "password": hashed}},
+		{name: "drop map", mode: RedactDrop, paths: []string{"password"},
+			value: logutils.Fields{"name": "jane", "password": "hunter2"},
+			want:  logutils.Fields{"name": "jane"}},
+		{name: "nested path", mode: RedactMask, paths: []string{"user.password"},
+			value: map[string]interface{}{"user": map[string]string{"password": "hunter2"}, "password": "kept"},
+			want:  map[string]interface{}{"user": map[string]interface{}{"password": redactedValue}, "password": "kept"}},
+		{name: "slice wildcard", mode: RedactDrop, paths: []string{"items.*.token"},
+			value: map[string]interface{}{"items": []map[string]string{{"id": "1", "token": "a"}, {"id": "2", "token": "b"}}},
+			want: map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}}}},
+		{name: "struct json names", mode: RedactMask, paths: []string{"password", "token"},
+			value: &user{Name: "jane", Password: "hunter2", Secret: "hidden", Token: "abc"},
+			want:  map[string]interface{}{"name": "jane", "password": redactedValue, "Token": redactedValue}},
+		{name: "no sensitive fields", mode: RedactMask, paths: []string{"password"},
+			value: logutils.Fields{"name": "jane"},
+			want:  logutils.Fields{"name": "jane"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFieldRedactor(tt.paths, tt.mode)
+			got, _ := r.redact(tt.value, nil)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redact() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactHashCorrelates(t *testing.T) {
+	r := newFieldRedactor([]string{"email"}, RedactHash)
+	first, second, other := r.redactedValue("jane@example.com"), r.redactedValue("jane@example.com"), r.redactedValue("joe@example.com")
+	if first != second {
+		t.Errorf("equal values hashed to %v and %v", first, second)
+	}
+	if first == other {
+		t.Errorf("different values hashed to %v", first)
+	}
+	if s, _ := first.(string); !strings.HasPrefix(s, "sha256:") || len(s) != len("sha256:")+12 {
+		t.Errorf("hash = %v, want sha256: followed by 12 hex digits", first)
+	}
+}
+
+func TestRedactRequestKeepsType(t *testing.T) {
+	r := newFieldRedactor([]string{"request.Headers.authorization", "request.ClientIP"}, RedactDrop)
+	request := RequestContext{Method: "GET", ClientIP: "10.0.0.1",
+		Headers: map[string][]string{"authorization": {"Bearer abc"}, "accept": {"*/*"}}}
+
+	got, changed := r.redact(logutils.Fields{"request": request}, nil)
+	if !changed {
+		t.Fatal("redact() did not change the request")
+	}
+	redacted, ok := got.(logutils.Fields)["request"].(RequestContext)
+	if !ok {
+		t.Fatalf("request = %T, want RequestContext", got.(logutils.Fields)["request"])
+	}
+	if redacted.ClientIP != "" || redacted.Method != "GET" {
+		t.Errorf("request = %+v", redacted)
+	}
+	if _, ok := redacted.Headers["authorization"]; ok || len(redacted.Headers["accept"]) != 1 {
+		t.Errorf("headers = %v", redacted.Headers)
+	}
+	if len(request.Headers) != 2 {
+		t.Error("redact() modified the original headers")
+	}
+}
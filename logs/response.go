@@ -0,0 +1,206 @@
+package logs
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+//responseRecorder wraps an http.ResponseWriter to record the status code and number of bytes written
+type responseRecorder struct {
+	w        http.ResponseWriter
+	status   int
+	size     int64
+	hijacked bool
+	capture  *bodyCapture
+	body     *capturedBody
+}
+
+//Header returns the header map of the underlying http.ResponseWriter
+func (r *responseRecorder) Header() http.Header {
+	return r.w.Header()
+}
+
+//WriteHeader records the status code and writes it to the underlying http.ResponseWriter
+//	Informational responses (1xx) other than 101 Switching Protocols are not final, so they are not recorded.
+func (r *responseRecorder) WriteHeader(code int) {
+	informational := code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols
+	if r.status == 0 && !informational {
+		r.status = code
+	}
+	r.w.WriteHeader(code)
+}
+
+//Write records the number of bytes written to the underlying http.ResponseWriter
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	n, err := r.w.Write(b)
+	r.size += int64(n)
+	r.captureResponse(b[:n])
+	return n, err
+}
+
+//Unwrap returns the underlying http.ResponseWriter
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.w
+}
+
+type flusher struct {
+	rec *responseRecorder
+}
+
+func (f flusher) Flush() {
+	if f.rec.status == 0 {
+		f.rec.status = http.StatusOK
+	}
+	f.rec.w.(http.Flusher).Flush()
+}
+
+type hijacker struct {
+	rec *responseRecorder
+}
+
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := h.rec.w.(http.Hijacker).Hijack()
+	if err == nil {
+		h.rec.hijacked = true
+	}
+	return conn, rw, err
+}
+
+type pusher struct {
+	rec *responseRecorder
+}
+
+func (p pusher) Push(target string, opts *http.PushOptions) error {
+	return p.rec.w.(http.Pusher).Push(target, opts)
+}
+
+type readerFrom struct {
+	rec *responseRecorder
+}
+
+func (r readerFrom) ReadFrom(src io.Reader) (int64, error) {
+	if r.rec.status == 0 {
+		r.rec.status = http.StatusOK
+	}
+
+	if r.rec.capture != nil {
+		src = io.TeeReader(src, captureWriter{r.rec})
+	}
+
+	n, err := r.rec.w.(io.ReaderFrom).ReadFrom(src)
+	r.rec.size += n
+	return n, err
+}
+
+//wrapResponseWriter wraps w in the provided recorder
+//	The returned http.ResponseWriter implements the same optional interfaces (http.Flusher, http.Hijacker,
+//	http.Pusher and io.ReaderFrom) as w, so type assertions made by handlers behave as if w was not wrapped
+func wrapResponseWriter(w http.ResponseWriter, rec *responseRecorder) http.ResponseWriter {
+	_, f := w.(http.Flusher)
+	_, h := w.(http.Hijacker)
+	_, p := w.(http.Pusher)
+	_, rf := w.(io.ReaderFrom)
+
+	switch {
+	case f && h && p && rf:
+		return struct {
+			*responseRecorder
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{rec, flusher{rec}, hijacker{rec}, pusher{rec}, readerFrom{rec}}
+	case f && h && p && !rf:
+		return struct {
+			*responseRecorder
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rec, flusher{rec}, hijacker{rec}, pusher{rec}}
+	case f && h && !p && rf:
+		return struct {
+			*responseRecorder
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{rec, flusher{rec}, hijacker{rec}, readerFrom{rec}}
+	case f && h && !p && !rf:
+		return struct {
+			*responseRecorder
+			http.Flusher
+			http.Hijacker
+		}{rec, flusher{rec}, hijacker{rec}}
+	case f && !h && p && rf:
+		return struct {
+			*responseRecorder
+			http.Flusher
+			http.Pusher
+			io.ReaderFrom
+		}{rec, flusher{rec}, pusher{rec}, readerFrom{rec}}
+	case f && !h && p && !rf:
+		return struct {
+			*responseRecorder
+			http.Flusher
+			http.Pusher
+		}{rec, flusher{rec}, pusher{rec}}
+	case f && !h && !p && rf:
+		return struct {
+			*responseRecorder
+			http.Flusher
+			io.ReaderFrom
+		}{rec, flusher{rec}, readerFrom{rec}}
+	case f && !h && !p && !rf:
+		return struct {
+			*responseRecorder
+			http.Flusher
+		}{rec, flusher{rec}}
+	case !f && h && p && rf:
+		return struct {
+			*responseRecorder
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{rec, hijacker{rec}, pusher{rec}, readerFrom{rec}}
+	case !f && h && p && !rf:
+		return struct {
+			*responseRecorder
+			http.Hijacker
+			http.Pusher
+		}{rec, hijacker{rec}, pusher{rec}}
+	case !f && h && !p && rf:
+		return struct {
+			*responseRecorder
+			http.Hijacker
+			io.ReaderFrom
+		}{rec, hijacker{rec}, readerFrom{rec}}
+	case !f && h && !p && !rf:
+		return struct {
+			*responseRecorder
+			http.Hijacker
+		}{rec, hijacker{rec}}
+	case !f && !h && p && rf:
+		return struct {
+			*responseRecorder
+			http.Pusher
+			io.ReaderFrom
+		}{rec, pusher{rec}, readerFrom{rec}}
+	case !f && !h && p && !rf:
+		return struct {
+			*responseRecorder
+			http.Pusher
+		}{rec, pusher{rec}}
+	case !f && !h && !p && rf:
+		return struct {
+			*responseRecorder
+			io.ReaderFrom
+		}{rec, readerFrom{rec}}
+	default:
+		return rec
+	}
+}
@@ -0,0 +1,231 @@
+package logs
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+//baseWriter is an http.ResponseWriter which implements none of the optional interfaces and records calls to them
+type baseWriter struct {
+	header http.Header
+	calls  []string
+}
+
+func (b *baseWriter) Header() http.Header {
+	if b.header == nil {
+		b.header = http.Header{}
+	}
+	return b.header
+}
+
+func (b *baseWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func (b *baseWriter) WriteHeader(code int) {}
+
+type testFlusher struct{ b *baseWriter }
+
+func (f testFlusher) Flush() { f.b.calls = append(f.b.calls, "Flush") }
+
+type testHijacker struct{ b *baseWriter }
+
+func (h testHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.b.calls = append(h.b.calls, "Hijack")
+	return nil, nil, nil
+}
+
+type testPusher struct{ b *baseWriter }
+
+func (p testPusher) Push(target string, opts *http.PushOptions) error {
+	p.b.calls = append(p.b.calls, "Push")
+	return nil
+}
+
+type testReaderFrom struct{ b *baseWriter }
+
+func (r testReaderFrom) ReadFrom(src io.Reader) (int64, error) {
+	r.b.calls = append(r.b.calls, "ReadFrom")
+	return io.Copy(ioutil.Discard, src)
+}
+
+func TestWrapResponseWriter(t *testing.T) {
+	type (
+		f  = testFlusher
+		h  = testHijacker
+		p  = testPusher
+		rf = testReaderFrom
+	)
+
+	tests := []struct {
+		name string
+		new  func(b *baseWriter) http.ResponseWriter
+	}{
+		{name: "none", new: func(b *baseWriter) http.ResponseWriter { return b }},
+		{name: "Flush", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				f
+			}{b, f{b}}
+		}},
+		{name: "Hijack", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				h
+			}{b, h{b}}
+		}},
+		{name: "Push", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				p
+			}{b, p{b}}
+		}},
+		{name: "ReadFrom", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				rf
+			}{b, rf{b}}
+		}},
+		{name: "Flush,Hijack", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				f
+				h
+			}{b, f{b}, h{b}}
+		}},
+		{name: "Flush,Push", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				f
+				p
+			}{b, f{b}, p{b}}
+		}},
+		{name: "Flush,ReadFrom", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				f
+				rf
+			}{b, f{b}, rf{b}}
+		}},
+		{name: "Hijack,Push", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				h
+				p
+			}{b, h{b}, p{b}}
+		}},
+		{name: "Hijack,ReadFrom", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				h
+				rf
+			}{b, h{b}, rf{b}}
+		}},
+		{name: "Push,ReadFrom", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				p
+				rf
+			}{b, p{b}, rf{b}}
+		}},
+		{name: "Flush,Hijack,Push", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				f
+				h
+				p
+			}{b, f{b}, h{b}, p{b}}
+		}},
+		{name: "Flush,Hijack,ReadFrom", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				f
+				h
+				rf
+			}{b, f{b}, h{b}, rf{b}}
+		}},
+		{name: "Flush,Push,ReadFrom", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				f
+				p
+				rf
+			}{b, f{b}, p{b}, rf{b}}
+		}},
+		{name: "Hijack,Push,ReadFrom", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				h
+				p
+				rf
+			}{b, h{b}, p{b}, rf{b}}
+		}},
+		{name: "Flush,Hijack,Push,ReadFrom", new: func(b *baseWriter) http.ResponseWriter {
+			return struct {
+				*baseWriter
+				f
+				h
+				p
+				rf
+			}{b, f{b}, h{b}, p{b}, rf{b}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &baseWriter{}
+			w := tt.new(b)
+			rec := &responseRecorder{w: w}
+			wrapped := wrapResponseWriter(w, rec)
+
+			var supported []string
+			if _, ok := w.(http.Flusher); ok {
+				supported = append(supported, "Flush")
+			}
+			if _, ok := w.(http.Hijacker); ok {
+				supported = append(supported, "Hijack")
+			}
+			if _, ok := w.(http.Pusher); ok {
+				supported = append(supported, "Push")
+			}
+			if _, ok := w.(io.ReaderFrom); ok {
+				supported = append(supported, "ReadFrom")
+			}
+			if got := strings.Join(supported, ","); got != strings.TrimPrefix(tt.name, "none") {
+				t.Fatalf("test writer supports %q, want %q", got, tt.name)
+			}
+
+			//Each optional interface is implemented by the wrapper only if w implements it, and calls are passed to w
+			var called []string
+			if flusher, ok := wrapped.(http.Flusher); ok {
+				flusher.Flush()
+				called = append(called, "Flush")
+			}
+			if hijacker, ok := wrapped.(http.Hijacker); ok {
+				hijacker.Hijack()
+				called = append(called, "Hijack")
+			}
+			if pusher, ok := wrapped.(http.Pusher); ok {
+				pusher.Push("/style.css", nil)
+				called = append(called, "Push")
+			}
+			if readerFrom, ok := wrapped.(io.ReaderFrom); ok {
+				readerFrom.ReadFrom(strings.NewReader("body"))
+				called = append(called, "ReadFrom")
+			}
+
+			if got, want := strings.Join(called, ","), strings.Join(supported, ","); got != want {
+				t.Errorf("wrapper implements %q, want %q", got, want)
+			}
+			if got, want := strings.Join(b.calls, ","), strings.Join(supported, ","); got != want {
+				t.Errorf("underlying writer received %q, want %q", got, want)
+			}
+			if rec.hijacked != strings.Contains(tt.name, "Hijack") {
+				t.Errorf("hijacked = %v", rec.hijacked)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package logs
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+//rotationTimeFormat is the format of the timestamp added to the names of rotated files
+const rotationTimeFormat = "20060102T150405.000"
+
+//maintenanceQueueSize is the number of rotated files which can wait for compression and retention before rotation blocks
+const maintenanceQueueSize = 16
+
+//RotationOpts provides configuration options for rotating a file sink
+type RotationOpts struct {
+	//MaxSize: The size in bytes after which the file is rotated (0 for no size limit)
+	MaxSize int64
+	//Interval: The interval at which the file is rotated (0 for no time-based rotation)
+	//			Rotations are aligned to multiples of the interval since the zero time in UTC (eg. 24h rotates at midnight UTC)
+	Interval time.Duration
+	//MaxBackups: The number of rotated files to keep (0 to keep all)
+	MaxBackups int
+	//MaxAge: The duration after which rotated files are removed (0 to keep all)
+	MaxAge time.Duration
+	//Compress: When true, rotated files are compressed using gzip
+	Compress bool
+	//ReopenOnSIGHUP: When true, the file is reopened when the process receives SIGHUP
+	//				  This allows external tools such as logrotate to move the file
+	ReopenOnSIGHUP bool
+}
+
+//rotatingFile is a file writer which rotates the file based on its size and age
+//	Rotated files are renamed to "<name>-<timestamp><ext>" in the same directory.
+type rotatingFile struct {
+	path string
+	opts RotationOpts
+
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+	closed       bool
+
+	backups    chan string
+	maintained chan struct{}
+	signals    chan os.Signal
+}
+
+//newRotatingFile opens the file at path to be rotated based on opts
+func newRotatingFile(path string, opts RotationOpts) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, opts: opts}
+	if err := r.open(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if opts.Compress || opts.MaxBackups > 0 || opts.MaxAge > 0 {
+		r.backups = make(chan string, maintenanceQueueSize)
+		r.maintained = make(chan struct{})
+		go r.maintain(r.backups, r.maintained)
+	}
+
+	if opts.ReopenOnSIGHUP {
+		r.signals = make(chan os.Signal, 1)
+		signal.Notify(r.signals, syscall.SIGHUP)
+		go r.handleSignals(r.signals)
+	}
+	return r, nil
+}
+
+//Write writes p to the file, rotating it first if needed
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+
+	now := time.Now()
+	if r.file == nil {
+		if err := r.open(now); err != nil {
+			return 0, err
+		}
+	}
+
+	exceedsSize := r.opts.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.opts.MaxSize
+	expired := !r.nextRotation.IsZero() && !now.Before(r.nextRotation)
+	if exceedsSize || expired {
+		if err := r.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+//Close stops handling signals, closes the file and waits for the maintenance of rotated files to finish
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.signals != nil {
+		signal.Stop(r.signals)
+		close(r.signals)
+		r.signals = nil
+	}
+	if r.backups != nil {
+		close(r.backups)
+		<-r.maintained
+		r.backups = nil
+	}
+	r.closed = true
+	return r.close()
+}
+
+//Reopen closes and reopens the file, eg. after it has been moved by an external tool
+func (r *rotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return os.ErrClosed
+	}
+	if err := r.close(); err != nil {
+		return err
+	}
+	return r.open(time.Now())
+}
+
+//handleSignals reopens the file on each signal until signals is closed
+func (r *rotatingFile) handleSignals(signals chan os.Signal) {
+	for range signals {
+		if err := r.Reopen(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to reopen log file %s, %v\n", r.path, err)
+		}
+	}
+}
+
+//open opens the file for appending
+//	The lock must be held by the caller.
+func (r *rotatingFile) open(now time.Time) error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	if r.opts.Interval > 0 {
+		r.nextRotation = now.Truncate(r.opts.Interval).Add(r.opts.Interval)
+	}
+	return nil
+}
+
+//close closes the file
+//	The lock must be held by the caller.
+func (r *rotatingFile) close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+//rotate renames the file, opens a new file and queues the rotated file for maintenance
+//	The lock must be held by the caller.
+func (r *rotatingFile) rotate(now time.Time) error {
+	if err := r.close(); err != nil {
+		return err
+	}
+
+	backup := r.backupName(now)
+	if err := os.Rename(r.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := r.open(now); err != nil {
+		return err
+	}
+
+	if r.backups != nil {
+		r.backups <- backup
+	}
+	return nil
+}
+
+//backupName returns an unused name for a file rotated at now
+//	A counter is added to the name if a file was already rotated in the same millisecond (eg. "app-<timestamp>-1.log").
+func (r *rotatingFile) backupName(now time.Time) string {
+	ext := filepath.Ext(r.path)
+	base := fmt.Sprintf("%s-%s", strings.TrimSuffix(r.path, ext), now.UTC().Format(rotationTimeFormat))
+	backup := base + ext
+	for i := 1; fileExists(backup) || fileExists(backup+".gz"); i++ {
+		backup = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	return backup
+}
+
+//fileExists returns true if a file exists at path
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return !os.IsNotExist(err)
+}
+
+//maintain compresses each rotated file if configured and removes rotated files exceeding the retention limits
+//	Rotated files are handled one at a time in the order they were rotated, so a file is never removed while it is
+//	being compressed. done is closed once backups is closed and all rotated files have been handled.
+func (r *rotatingFile) maintain(backups chan string, done chan struct{}) {
+	defer close(done)
+
+	for backup := range backups {
+		if r.opts.Compress {
+			if err := compressFile(backup); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "Failed to compress log file %s, %v\n", backup, err)
+			}
+		}
+
+		if err := r.removeBackups(time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove rotated log files of %s, %v\n", r.path, err)
+		}
+	}
+}
+
+//removeBackups removes the oldest rotated files exceeding MaxBackups and the rotated files older than MaxAge
+func (r *rotatingFile) removeBackups(now time.Time) error {
+	if r.opts.MaxBackups <= 0 && r.opts.MaxAge <= 0 {
+		return nil
+	}
+
+	type backupFile struct {
+		path    string
+		time    time.Time
+		counter int
+	}
+
+	dir := filepath.Dir(r.path)
+	ext := filepath.Ext(r.path)
+	prefix := strings.TrimSuffix(filepath.Base(r.path), ext) + "-"
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		suffix := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		parts := strings.SplitN(suffix, "-", 2)
+		rotated, err := time.Parse(rotationTimeFormat, parts[0])
+		if err != nil {
+			continue
+		}
+		counter := 0
+		if len(parts) == 2 {
+			counter, err = strconv.Atoi(parts[1])
+			if err != nil {
+				continue
+			}
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), time: rotated, counter: counter})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].time.Equal(backups[j].time) {
+			return backups[i].counter > backups[j].counter
+		}
+		return backups[i].time.After(backups[j].time)
+	})
+
+	for i, backup := range backups {
+		tooMany := r.opts.MaxBackups > 0 && i >= r.opts.MaxBackups
+		tooOld := r.opts.MaxAge > 0 && now.Sub(backup.time) > r.opts.MaxAge
+		if tooMany || tooOld {
+			if err := os.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//compressFile compresses the file at path to path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,190 @@
+package logs
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+//writeFiles creates empty files with the provided names in dir
+func writeFiles(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+//listFiles returns the sorted names of the files in dir
+func listFiles(t *testing.T, dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestBackupName(t *testing.T) {
+	now := time.Date(2021, 3, 4, 5, 6, 7, 890000000, time.UTC)
+	stamp := "app-20210304T050607.890"
+
+	tests := []struct {
+		name     string
+		existing []string
+		want     string
+	}{
+		{name: "unused", want: stamp + ".log"},
+		{name: "rotated in same millisecond", existing: []string{stamp + ".log"}, want: stamp + "-1.log"},
+		{name: "compressed in same millisecond", existing: []string{stamp + ".log.gz"}, want: stamp + "-1.log"},
+		{name: "several in same millisecond", existing: []string{stamp + ".log.gz", stamp + "-1.log.gz", stamp + "-2.log"}, want: stamp + "-3.log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rotate")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			writeFiles(t, dir, tt.existing...)
+
+			r := &rotatingFile{path: filepath.Join(dir, "app.log")}
+			if got := filepath.Base(r.backupName(now)); got != tt.want {
+				t.Errorf("backupName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveBackups(t *testing.T) {
+	now := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+	files := []string{
+		"app.log",
+		"app-20210301T000000.000.log.gz",
+		"app-20210305T000000.000.log.gz",
+		"app-20210309T000000.000.log",
+		"app-20210309T000000.000-1.log",
+		"app-20210309T000000.000-2.log.gz",
+		"app-notes.log",
+		"other-20210301T000000.000.log",
+	}
+
+	tests := []struct {
+		name string
+		opts RotationOpts
+		want []string
+	}{
+		{name: "no limits", opts: RotationOpts{}, want: files},
+		{name: "max backups keeps newest counters", opts: RotationOpts{MaxBackups: 2}, want: []string{
+			"app.log", "app-20210309T000000.000-1.log", "app-20210309T000000.000-2.log.gz", "app-notes.log", "other-20210301T000000.000.log"}},
+		{name: "max backups across times", opts: RotationOpts{MaxBackups: 4}, want: []string{
+			"app.log", "app-20210305T000000.000.log.gz", "app-20210309T000000.000.log", "app-20210309T000000.000-1.log",
+			"app-20210309T000000.000-2.log.gz", "app-notes.log", "other-20210301T000000.000.log"}},
+		{name: "max age", opts: RotationOpts{MaxAge: 7 * 24 * time.Hour}, want: []string{
+			"app.log", "app-20210305T000000.000.log.gz", "app-20210309T000000.000.log", "app-20210309T000000.000-1.log",
+			"app-20210309T000000.000-2.log.gz", "app-notes.log", "other-20210301T000000.000.log"}},
+		{name: "max age and max backups", opts: RotationOpts{MaxBackups: 3, MaxAge: 2 * 24 * time.Hour}, want: []string{
+			"app.log", "app-20210309T000000.000.log", "app-20210309T000000.000-1.log", "app-20210309T000000.000-2.log.gz",
+			"app-notes.log", "other-20210301T000000.000.log"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rotate")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			writeFiles(t, dir, files...)
+
+			r := &rotatingFile{path: filepath.Join(dir, "app.log"), opts: tt.opts}
+			if err := r.removeBackups(now); err != nil {
+				t.Fatal(err)
+			}
+
+			want := append([]string{}, tt.want...)
+			sort.Strings(want)
+			if got := listFiles(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("files = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCompressFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	if err := ioutil.WriteFile(path, []byte("line 1\nline 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(path) {
+		t.Error("original file was not removed")
+	}
+
+	file, err := os.Open(path + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "line 1\nline 2\n" {
+		t.Errorf("decompressed data = %q", data)
+	}
+}
+
+func TestRotatingFileMaintenance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := newRotatingFile(filepath.Join(dir, "app.log"), RotationOpts{MaxSize: 10, MaxBackups: 3, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		if _, err := r.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	//Close waits for maintenance, so every kept backup is compressed and the oldest backups are removed
+	files := listFiles(t, dir)
+	if len(files) != 4 {
+		t.Fatalf("files = %v, want the log file and 3 backups", files)
+	}
+	for _, name := range files {
+		if name != "app.log" && !strings.HasSuffix(name, ".log.gz") {
+			t.Errorf("backup %s was not compressed", name)
+		}
+	}
+}
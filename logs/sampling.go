@@ -0,0 +1,92 @@
+package logs
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+//SamplingOpts provides configuration options for sampling request logs
+//	Sampling decisions are made per trace by hashing the trace ID, so every service in a call chain using the same
+//	rates makes the same decision. Warn and Error logs are always printed, regardless of the decision.
+type SamplingOpts struct {
+	//Rate: The fraction of traces (0 to 1) whose Debug and Info request logs are printed
+	Rate float64
+	//RouteRates: Sampling rates for specific routes, which override Rate. The first matching entry is used.
+	RouteRates []RouteSampleRate
+	//RespectParent: When true, the sampling decision received in the trace context of a request (eg. the W3C
+	//				 traceparent sampled flag) is used instead of making a new decision when it is available
+	RespectParent bool
+}
+
+//RouteSampleRate defines the sampling rate for requests matching a route
+type RouteSampleRate struct {
+	//Route: A glob pattern matching the route template set using Log.SetRoute, or the request path if the route is not set
+	Route string
+	//Rate: The fraction of traces (0 to 1) whose Debug and Info request logs are printed
+	Rate float64
+}
+
+//sampler makes sampling decisions for request logs
+type sampler struct {
+	rate          float64
+	routeRates    []RouteSampleRate
+	respectParent bool
+}
+
+func newSampler(opts *SamplingOpts) *sampler {
+	return &sampler{rate: opts.Rate, routeRates: opts.RouteRates, respectParent: opts.RespectParent}
+}
+
+//sample returns true if the Debug and Info logs of the request should be printed
+//	traceID: The trace ID of the request
+//	route: The route template of the request, or the path if the route is unknown
+//	parentFlags: The trace flags received in the trace context of the request (empty if unknown)
+func (s *sampler) sample(traceID string, route string, parentFlags string) bool {
+	if s.respectParent && parentFlags != "" {
+		return traceFlagsSampled(parentFlags)
+	}
+
+	rate := s.rate
+	for _, routeRate := range s.routeRates {
+		if matchGlob(routeRate.Route, route) {
+			rate = routeRate.Rate
+			break
+		}
+	}
+
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+
+	//IDs are normalized so that the same trace received in different formats (eg. UUID or W3C hex) has the same hash
+	hash := fnv.New64a()
+	hash.Write([]byte(w3cTraceID(traceID)))
+	return float64(hash.Sum64())/float64(math.MaxUint64) < rate
+}
+
+//sampleFlags returns the trace flags representing a sampling decision
+func sampleFlags(sampled bool) string {
+	if sampled {
+		return sampledTraceFlags
+	}
+	return unsampledTraceFlags
+}
+
+//applySampling makes the sampling decision for a request log and records it in the trace flags to be propagated
+//	parentFlags: The trace flags received in the trace context of the request (empty if unknown)
+func (l *Log) applySampling(parentFlags string) {
+	if l.logger == nil || l.logger.sampler == nil {
+		return
+	}
+
+	route := l.request.Route
+	if route == "" {
+		route = l.request.Path
+	}
+
+	l.sampled = l.logger.sampler.sample(l.traceID, route, parentFlags)
+	l.traceFlags = sampleFlags(l.sampled)
+}
@@ -0,0 +1,211 @@
+package logs
+
+import (
+	"regexp"
+
+	"github.com/rokmetro/logging-library/logutils"
+	"github.com/sirupsen/logrus"
+)
+
+//scrubRule replaces all matches of a regular expression in a string
+type scrubRule struct {
+	regex   *regexp.Regexp
+	replace func(match []string) string
+}
+
+//scrubber removes sensitive data from log messages and field values
+type scrubber struct {
+	rules []scrubRule
+}
+
+//newScrubber creates a scrubber using the provided built-in detectors and custom regular expressions
+//	Returns the scrubber and any patterns that could not be compiled
+func newScrubber(detectors []ScrubDetector, patterns []string) (*scrubber, []string) {
+	s := &scrubber{}
+	for _, detector := range detectors {
+		if rule, ok := detectorRule(detector); ok {
+			s.rules = append(s.rules, rule)
+		}
+	}
+
+	var invalid []string
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			invalid = append(invalid, pattern)
+			continue
+		}
+		s.rules = append(s.rules, scrubRule{regex: regex, replace: replaceWith("[REDACTED]")})
+	}
+	return s, invalid
+}
+
+//detectorRule returns the scrubRule for a built-in detector
+func detectorRule(detector ScrubDetector) (scrubRule, bool) {
+	replacement := "[REDACTED:" + string(detector) + "]"
+
+	switch detector {
+	case ScrubEmail:
+		return scrubRule{regex: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), replace: replaceWith(replacement)}, true
+	case ScrubJWT:
+		return scrubRule{regex: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), replace: replaceWith(replacement)}, true
+	case ScrubBearerToken:
+		return scrubRule{regex: regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+			replace: func(match []string) string { return match[1] + replacement }}, true
+	case ScrubCreditCard:
+		return scrubRule{regex: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+			replace: func(match []string) string {
+				if luhnValid(match[0]) {
+					return replacement
+				}
+				return match[0]
+			}}, true
+	case ScrubSSN:
+		return scrubRule{regex: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), replace: replaceWith(replacement)}, true
+	case ScrubAWSKey:
+		return scrubRule{regex: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b|(?i)(secret_?access_?key["']?\s*[=:]\s*["']?)[A-Za-z0-9/+]{40}`),
+			replace: func(match []string) string { return match[1] + replacement }}, true
+	default:
+		return scrubRule{}, false
+	}
+}
+
+func replaceWith(replacement string) func(match []string) string {
+	return func(match []string) string {
+		return replacement
+	}
+}
+
+//luhnValid returns true if the digits in value pass the Luhn checksum
+func luhnValid(value string) bool {
+	sum := 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+//scrubString removes all sensitive data from value
+func (s *scrubber) scrubString(value string) string {
+	for _, rule := range s.rules {
+		value = rule.regex.ReplaceAllStringFunc(value, func(match string) string {
+			return rule.replace(rule.regex.FindStringSubmatch(match))
+		})
+	}
+	return value
+}
+
+//scrubValue returns a copy of value with all sensitive data removed
+//	Strings, errors, and maps and slices containing them are scrubbed. Other values are returned unchanged.
+func (s *scrubber) scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return s.scrubString(v)
+	case error:
+		return s.scrubString(v.Error())
+	case logutils.Fields:
+		return logutils.Fields(s.scrubMap(v))
+	case logrus.Fields:
+		return logrus.Fields(s.scrubMap(v))
+	case map[string]interface{}:
+		return s.scrubMap(v)
+	case map[string]string:
+		scrubbed := make(map[string]string, len(v))
+		for key, val := range v {
+			scrubbed[key] = s.scrubString(val)
+		}
+		return scrubbed
+	case map[string][]string:
+		return s.scrubStringsMap(v)
+	case []string:
+		return s.scrubStrings(v)
+	case []interface{}:
+		scrubbed := make([]interface{}, len(v))
+		for i, val := range v {
+			scrubbed[i] = s.scrubValue(val)
+		}
+		return scrubbed
+	case RequestContext:
+		v.Path = s.scrubString(v.Path)
+		v.Query = s.scrubStringsMap(v.Query)
+		v.Headers = s.scrubStringsMap(v.Headers)
+		return v
+	default:
+		return value
+	}
+}
+
+func (s *scrubber) scrubMap(m map[string]interface{}) map[string]interface{} {
+	scrubbed := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		scrubbed[key] = s.scrubValue(val)
+	}
+	return scrubbed
+}
+
+func (s *scrubber) scrubStringsMap(m map[string][]string) map[string][]string {
+	if m == nil {
+		return nil
+	}
+
+	scrubbed := make(map[string][]string, len(m))
+	for key, val := range m {
+		scrubbed[key] = s.scrubStrings(val)
+	}
+	return scrubbed
+}
+
+func (s *scrubber) scrubStrings(values []string) []string {
+	scrubbed := make([]string, len(values))
+	for i, val := range values {
+		scrubbed[i] = s.scrubString(val)
+	}
+	return scrubbed
+}
+
+//scrubHook is a logrus hook which scrubs the message and fields of each entry before it is formatted
+type scrubHook struct {
+	scrubber *scrubber
+}
+
+//Levels returns the levels the hook is fired for
+func (h *scrubHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+//Fire scrubs the message and fields of the entry
+//	Standard identifier fields are not scrubbed. Nested values are copied so that the caller's data is not modified.
+func (h *scrubHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.scrubber.scrubString(entry.Message)
+	for key, value := range entry.Data {
+		if isStandardField(key) {
+			continue
+		}
+		entry.Data[key] = h.scrubber.scrubValue(value)
+	}
+	return nil
+}
+
+//isStandardField returns true if key is one of the identifier fields added to every log by the library
+func isStandardField(key string) bool {
+	switch key {
+	case "service_name", "trace_id", "span_id", "parent_span_id", "function_name":
+		return true
+	default:
+		return false
+	}
+}
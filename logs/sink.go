@@ -0,0 +1,219 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+//Sink defines a destination logs are written to
+type Sink struct {
+	//Type: The type of destination
+	Type SinkType
+	//Path: The path of the file logs are appended to (SinkFile only)
+	Path string
+	//Rotation: When not nil, the file is rotated based on its size and age (SinkFile only)
+	Rotation *RotationOpts
+	//Writer: The writer logs are written to (SinkWriter only)
+	Writer io.Writer
+	//Level: The minimum level of logs written to the sink (Default: all logs printed by the Logger)
+	//		 Logs below the level of the Logger are not written to any sink
+	Level logLevel
+	//Format: The format logs are written in (Default: the format selected by LoggerOpts.JsonFmt)
+	Format OutputFormat
+	//Formatter: A custom formatter used instead of Format
+	Formatter logrus.Formatter
+}
+
+//sinkOutput writes log entries to a sink
+type sinkOutput struct {
+	writer    io.Writer
+	closer    io.Closer
+	level     logrus.Level
+	formatter logrus.Formatter
+	mu        sync.Mutex
+}
+
+//newSinkOutput opens the destination of sink
+//	defaultFormatter: The formatter used if the sink does not define a format
+func newSinkOutput(sink Sink, defaultFormatter logrus.Formatter) (*sinkOutput, error) {
+	var writer io.Writer
+	var closer io.Closer
+	switch sink.Type {
+	case SinkStdout:
+		writer = os.Stdout
+	case SinkStderr:
+		writer = os.Stderr
+	case SinkFile:
+		if sink.Path == "" {
+			return nil, errors.New("missing file path")
+		}
+		if sink.Rotation != nil {
+			file, err := newRotatingFile(sink.Path, *sink.Rotation)
+			if err != nil {
+				return nil, err
+			}
+			writer, closer = file, file
+			break
+		}
+		file, err := os.OpenFile(sink.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		writer, closer = file, file
+	case SinkWriter:
+		if sink.Writer == nil {
+			return nil, errors.New("missing writer")
+		}
+		writer = sink.Writer
+	default:
+		return nil, fmt.Errorf("invalid sink type %s", sink.Type)
+	}
+
+	level := logrus.TraceLevel
+	if sink.Level != "" {
+		sinkLevel, ok := logrusLevel(sink.Level)
+		if !ok {
+			return nil, fmt.Errorf("invalid level %s", sink.Level)
+		}
+		level = sinkLevel
+	}
+
+	formatter := sink.Formatter
+	if formatter == nil {
+		formatter = newFormatter(sink.Format, defaultFormatter)
+	}
+
+	return &sinkOutput{writer: writer, closer: closer, level: level, formatter: formatter}, nil
+}
+
+//format formats entry for the sink
+//	Returns nil if the level of entry is not enabled for the sink. The result is only valid until the next call.
+func (s *sinkOutput) format(entry *logrus.Entry) []byte {
+	if entry.Level > s.level {
+		return nil
+	}
+
+	//logrus formatters write to the buffer of the entry if it is set, so it is reset for each sink
+	if entry.Buffer != nil {
+		entry.Buffer.Reset()
+	}
+	serialized, err := s.formatter.Format(entry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to format log entry for sink, %v\n", err)
+		return nil
+	}
+	return serialized
+}
+
+//writeBytes writes a formatted entry to the sink
+func (s *sinkOutput) writeBytes(serialized []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.writer.Write(serialized); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write to log sink, %v\n", err)
+	}
+}
+
+//sinkFormatter is a logrus formatter which writes each entry to a list of sinks
+//	logrus writes the output of its formatter to a single writer, so entries are written to the sinks while they are
+//	formatted and no output is returned. The output of the logrus logger should be ioutil.Discard.
+//	If async is set, the formatted entries are queued to be written in the background instead.
+type sinkFormatter struct {
+	sinks []*sinkOutput
+	async *asyncWriter
+}
+
+//newSinkFormatter opens the sinks which logs are written to
+//	Sinks which cannot be opened are skipped and returned as errors. If no sinks can be opened, nil is returned.
+//	defaultFormatter: The formatter used by sinks which do not define a format
+func newSinkFormatter(sinks []Sink, defaultFormatter logrus.Formatter) (*sinkFormatter, []error) {
+	var outputs []*sinkOutput
+	var errs []error
+	for i, sink := range sinks {
+		output, err := newSinkOutput(sink, defaultFormatter)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sink %d (%s): %v", i, sink.Type, err))
+			continue
+		}
+		outputs = append(outputs, output)
+	}
+
+	if len(outputs) == 0 {
+		return nil, errs
+	}
+	return &sinkFormatter{sinks: outputs}, errs
+}
+
+//Format writes the entry to each sink
+func (f *sinkFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	for _, sink := range f.sinks {
+		serialized := sink.format(entry)
+		if serialized == nil {
+			continue
+		}
+
+		if f.async == nil {
+			sink.writeBytes(serialized)
+			continue
+		}
+		//The serialized entry may share the buffer of the entry, which is reused after formatting
+		data := make([]byte, len(serialized))
+		copy(data, serialized)
+		f.async.enqueue(asyncItem{sink: sink, data: data, level: entry.Level})
+	}
+	return nil, nil
+}
+
+//flush waits until all queued entries have been written or ctx is done
+func (f *sinkFormatter) flush(ctx context.Context) error {
+	if f.async == nil {
+		return nil
+	}
+	return f.async.flush(ctx)
+}
+
+//close writes all queued entries and closes the files of the sinks
+//	Entries logged afterwards are written synchronously, except to closed files.
+func (f *sinkFormatter) close() error {
+	if f.async != nil {
+		f.async.close()
+	}
+
+	var errs []string
+	for _, sink := range f.sinks {
+		if sink.closer == nil {
+			continue
+		}
+		if err := sink.closer.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close log sinks: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+//newFormatter returns the logrus formatter for format
+//	defaultFormatter: The formatter returned if format is not set
+func newFormatter(format OutputFormat, defaultFormatter logrus.Formatter) logrus.Formatter {
+	switch format {
+	case FormatJSON:
+		return &logrus.JSONFormatter{}
+	case FormatText:
+		return &logrus.TextFormatter{}
+	case FormatECS:
+		return &ecsFormatter{}
+	case FormatOTel:
+		return &otelFormatter{}
+	default:
+		return defaultFormatter
+	}
+}
@@ -0,0 +1,92 @@
+package logs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rokmetro/logging-library/logutils"
+	"github.com/sirupsen/logrus"
+)
+
+//Span struct defines a timed operation within a request
+//	The embedded Log can be used to print logs associated with the span
+type Span struct {
+	*Log
+	name       string
+	start      time.Time
+	status     SpanStatus
+	attributes logutils.Fields
+	ended      bool
+}
+
+//StartSpan starts a child span of the log with the provided name
+//	The child span shares the trace ID of the log and records the span ID of the log as its parent.
+//	The span starts with a copy of the log context, so SetContext on the span does not modify the log or other spans.
+//	End() must be called on the returned span when the operation is complete.
+//	Params:
+//		name: A meaningful name for the operation (eg. "find user", "GET auth-service")
+func (l *Log) StartSpan(name string) *Span {
+	if l == nil {
+		return &Span{name: name, start: time.Now(), status: SpanStatusUnset, attributes: logutils.Fields{}}
+	}
+
+	context := make(logutils.Fields, len(l.context))
+	for key, value := range l.context {
+		context[key] = value
+	}
+
+	child := &Log{logger: l.logger, traceID: l.traceID, spanID: uuid.New().String(), traceFlags: l.traceFlags, traceState: l.traceState,
+		request: l.request, context: context, suppress: l.suppress, sampled: l.sampled, parent: l}
+	return &Span{Log: child, name: name, start: time.Now(), status: SpanStatusUnset, attributes: logutils.Fields{}}
+}
+
+//Name returns the name of the span
+func (s *Span) Name() string {
+	if s == nil {
+		return ""
+	}
+	return s.name
+}
+
+//SetStatus sets the final status of the span
+func (s *Span) SetStatus(status SpanStatus) {
+	if s == nil {
+		return
+	}
+	s.status = status
+}
+
+//SetAttribute sets an attribute of the span to be printed when the span ends
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attributes[key] = value
+}
+
+//End prints the "Span Complete" log with the duration, status and attributes of the span
+//	Calling End more than once has no effect
+func (s *Span) End() {
+	if s == nil || s.ended {
+		return
+	}
+	s.ended = true
+
+	l := s.Log
+	if l == nil || l.logger == nil {
+		return
+	}
+
+	if l.suppress && !l.logged() {
+		return
+	}
+
+	fields := l.getRequestFields()
+	fields["span_name"] = s.name
+	fields["duration_ms"] = float64(time.Since(s.start)) / float64(time.Millisecond)
+	fields["status"] = s.status
+	if len(s.attributes) > 0 {
+		fields["attributes"] = s.attributes
+	}
+	l.output(logrus.InfoLevel, "Span Complete", fields)
+}
@@ -0,0 +1,42 @@
+package logs
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestSpanContextConcurrent(t *testing.T) {
+	logger, _ := newTestLogger(LoggerOpts{})
+	log := logger.NewRequestLog(httptest.NewRequest("GET", "/", nil))
+	log.SetContext("user", "alice")
+
+	spans := make([]*Span, 2)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range spans {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			span := log.StartSpan(fmt.Sprintf("span %d", i))
+			<-start
+			for j := 0; j < 100; j++ {
+				span.SetContext(fmt.Sprintf("key %d", j), i)
+			}
+			span.End()
+			spans[i] = span
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if len(log.context) != 1 || log.context["user"] != "alice" {
+		t.Errorf("log context = %v, want only the user set on the log", log.context)
+	}
+	for i, span := range spans {
+		if len(span.context) != 101 || span.context["user"] != "alice" || span.context["key 0"] != i {
+			t.Errorf("span %d context has %d fields, want 101 fields including the log context", i, len(span.context))
+		}
+	}
+}
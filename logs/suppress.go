@@ -0,0 +1,161 @@
+package logs
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//patternCache caches compiled regular expressions and parsed CIDRs used to match requests
+var patternCache sync.Map
+
+//matchGlob returns true if value matches the glob pattern
+//	"*" matches any sequence of characters except "/", "**" matches any sequence of characters and
+//	"?" matches any single character except "/"
+func matchGlob(pattern string, value string) bool {
+	regex := cachedRegexp("glob:"+pattern, func() string { return globExpr(pattern, "[^/]") })
+	return regex != nil && regex.MatchString(value)
+}
+
+//matchValueGlob returns true if value matches the glob pattern
+//	"*" matches any sequence of characters and "?" matches any single character
+func matchValueGlob(pattern string, value string) bool {
+	regex := cachedRegexp("value:"+pattern, func() string { return globExpr(pattern, ".") })
+	return regex != nil && regex.MatchString(value)
+}
+
+//matchRegexp returns true if value matches the regular expression
+//	Invalid regular expressions never match
+func matchRegexp(expr string, value string) bool {
+	regex := cachedRegexp("regexp:"+expr, func() string { return expr })
+	return regex != nil && regex.MatchString(value)
+}
+
+//globExpr converts a glob pattern to an anchored regular expression
+//	char: The expression matched by "?", and repeatedly by "*"
+func globExpr(pattern string, char string) string {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString(char + "*")
+		case pattern[i] == '?':
+			expr.WriteString(char)
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	return expr.String()
+}
+
+//cachedRegexp returns the compiled regular expression for key, compiling the expression returned by expr if needed
+//	Returns nil if the expression is invalid
+func cachedRegexp(key string, expr func() string) *regexp.Regexp {
+	if cached, ok := patternCache.Load(key); ok {
+		regex, _ := cached.(*regexp.Regexp)
+		return regex
+	}
+
+	regex, err := regexp.Compile(expr())
+	if err != nil {
+		regex = nil
+	}
+	patternCache.Store(key, regex)
+	return regex
+}
+
+//matchCIDRs returns true if ip is contained in any of the provided CIDRs
+//	Single IP addresses are also accepted in place of CIDRs. Invalid CIDRs never match.
+func matchCIDRs(cidrs []string, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range cidrs {
+		if network := cachedCIDR(cidr); network != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+//cachedCIDR returns the parsed network for cidr
+//	Returns nil if cidr is invalid
+func cachedCIDR(cidr string) *net.IPNet {
+	key := "cidr:" + cidr
+	if cached, ok := patternCache.Load(key); ok {
+		network, _ := cached.(*net.IPNet)
+		return network
+	}
+
+	if !strings.Contains(cidr, "/") {
+		if strings.Contains(cidr, ":") {
+			cidr += "/128"
+		} else {
+			cidr += "/32"
+		}
+	}
+
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		network = nil
+	}
+	patternCache.Store(key, network)
+	return network
+}
+
+//matchAnyValue returns true if any of the values match the glob pattern
+func matchAnyValue(pattern string, values []string) bool {
+	for _, value := range values {
+		if matchValueGlob(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+//remoteHost returns the host of a RemoteAddr, removing the port if present
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+//suppressRequest returns true if the request matches any of the SuppressRequests of the Logger
+//	route: The matched route template of the request (empty if unknown)
+//	clientIP: The IP address of the client which sent the request
+func (l *Logger) suppressRequest(r *http.Request, route string, clientIP string) bool {
+	for _, props := range l.suppressRequests {
+		if props.matchRequest(r, route, clientIP) {
+			return true
+		}
+	}
+	return false
+}
+
+//NewKubernetesProbeHttpRequestProperties creates an HttpRequestProperties object for Kubernetes liveness, readiness and startup probes
+//	Path: The path that the probes are performed on. If empty, probes on any path are matched.
+func NewKubernetesProbeHttpRequestProperties(path string) HttpRequestProperties {
+	return HttpRequestProperties{Method: "GET", Path: path, UserAgentContains: "kube-probe/"}
+}
+
+//NewGcpHealthCheckHttpRequestProperties creates an HttpRequestProperties object for Google Cloud load balancer health checks
+//	Path: The path that the health checks are performed on. If empty, health checks on any path are matched.
+func NewGcpHealthCheckHttpRequestProperties(path string) HttpRequestProperties {
+	return HttpRequestProperties{Method: "GET", Path: path, UserAgent: "GoogleHC/1.0", RemoteCIDRs: []string{"35.191.0.0/16", "130.211.0.0/22"}}
+}
+
+//NewAzureHealthCheckHttpRequestProperties creates an HttpRequestProperties object for Azure Load Balancer health probes
+//	Path: The path that the health probes are performed on. If empty, health probes on any path are matched.
+func NewAzureHealthCheckHttpRequestProperties(path string) HttpRequestProperties {
+	return HttpRequestProperties{Method: "GET", Path: path, RemoteCIDRs: []string{"168.63.129.16/32"}}
+}
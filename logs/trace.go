@@ -0,0 +1,250 @@
+package logs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	headerTraceID     = "trace-id"
+	headerSpanID      = "span-id"
+	headerTraceparent = "traceparent"
+	headerTracestate  = "tracestate"
+
+	traceparentVersion  = "00"
+	sampledTraceFlags   = "01"
+	unsampledTraceFlags = "00"
+)
+
+//TraceContext contains the trace information propagated between services
+type TraceContext struct {
+	TraceID    string //ID of the trace shared by all services handling a request
+	SpanID     string //ID of the span that sent the request
+	TraceFlags string //W3C trace flags as a 2 character hex string ("01" if sampled). Empty if unknown.
+	TraceState string //Vendor specific trace state
+}
+
+//Sampled returns false only if the trace flags indicate that the trace is not sampled
+func (tc TraceContext) Sampled() bool {
+	return traceFlagsSampled(tc.TraceFlags)
+}
+
+//Propagator defines an interface for reading and writing trace context in HTTP headers
+type Propagator interface {
+	//Extract reads the trace context from the provided headers
+	//	Returns false if the headers do not contain a valid trace context
+	Extract(header http.Header) (TraceContext, bool)
+	//Inject writes the provided trace context to the provided headers
+	Inject(header http.Header, tc TraceContext)
+}
+
+//NewPropagator returns the built-in Propagator for the provided format
+//	Returns nil if the format is not recognized
+func NewPropagator(format PropagationFormat) Propagator {
+	switch format {
+	case PropagationLegacy:
+		return LegacyPropagator{}
+	case PropagationW3C:
+		return W3CPropagator{}
+	case PropagationB3:
+		return B3Propagator{}
+	case PropagationB3Single:
+		return B3Propagator{SingleHeader: true}
+	default:
+		return nil
+	}
+}
+
+//newPropagators returns the Propagators for the provided formats followed by the custom propagators
+func newPropagators(formats []PropagationFormat, custom []Propagator) []Propagator {
+	propagators := make([]Propagator, 0, len(formats)+len(custom))
+	for _, format := range formats {
+		if propagator := NewPropagator(format); propagator != nil {
+			propagators = append(propagators, propagator)
+		}
+	}
+
+	for _, propagator := range custom {
+		if propagator != nil {
+			propagators = append(propagators, propagator)
+		}
+	}
+	return propagators
+}
+
+//extractTraceContext reads the trace context from the provided headers
+//	The first propagator that finds a valid trace context in the headers is used
+//	Returns false if none of the propagators found a trace context
+func extractTraceContext(header http.Header, propagators []Propagator) (TraceContext, bool) {
+	for _, propagator := range propagators {
+		if tc, ok := propagator.Extract(header); ok {
+			return tc, true
+		}
+	}
+
+	return TraceContext{}, false
+}
+
+//injectTraceContext writes the trace context to the provided headers using each of the propagators
+func injectTraceContext(header http.Header, propagators []Propagator, tc TraceContext) {
+	//An empty trace context would be sent as the same fixed trace, merging unrelated requests downstream
+	if tc.TraceID == "" {
+		return
+	}
+
+	for _, propagator := range propagators {
+		propagator.Inject(header, tc)
+	}
+}
+
+//LegacyPropagator reads and writes the trace context in the trace-id and span-id headers
+type LegacyPropagator struct{}
+
+//Extract reads the trace context from the trace-id and span-id headers
+func (p LegacyPropagator) Extract(header http.Header) (TraceContext, bool) {
+	traceID := header.Get(headerTraceID)
+	if traceID == "" {
+		return TraceContext{}, false
+	}
+
+	return TraceContext{TraceID: traceID, SpanID: header.Get(headerSpanID)}, true
+}
+
+//Inject writes the trace context to the trace-id and span-id headers
+//	Nothing is written if the trace context has no trace ID.
+func (p LegacyPropagator) Inject(header http.Header, tc TraceContext) {
+	if tc.TraceID == "" {
+		return
+	}
+
+	header.Set(headerTraceID, tc.TraceID)
+	header.Set(headerSpanID, tc.SpanID)
+}
+
+//W3CPropagator reads and writes the trace context in the W3C Trace Context traceparent and tracestate headers
+type W3CPropagator struct{}
+
+//Extract reads the trace context from the traceparent and tracestate headers
+func (p W3CPropagator) Extract(header http.Header) (TraceContext, bool) {
+	tc, ok := parseTraceparent(header.Get(headerTraceparent))
+	if !ok {
+		return TraceContext{}, false
+	}
+
+	//tracestate may be split across multiple header lines
+	tc.TraceState = strings.Join(header.Values(headerTracestate), ",")
+	return tc, true
+}
+
+//Inject writes the trace context to the traceparent and tracestate headers
+//	Nothing is written if the trace context has no trace ID.
+func (p W3CPropagator) Inject(header http.Header, tc TraceContext) {
+	if tc.TraceID == "" {
+		return
+	}
+
+	traceFlags := tc.TraceFlags
+	if traceFlags == "" {
+		traceFlags = sampledTraceFlags
+	}
+
+	header.Set(headerTraceparent, formatTraceparent(w3cTraceID(tc.TraceID), w3cSpanID(tc.SpanID), traceFlags))
+	if tc.TraceState != "" {
+		header.Set(headerTracestate, tc.TraceState)
+	} else {
+		header.Del(headerTracestate)
+	}
+}
+
+//parseTraceparent parses and validates a W3C traceparent header value
+//	Format: {version}-{trace-id}-{parent-id}-{trace-flags}
+func parseTraceparent(value string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, false
+	}
+
+	version, traceID, parentID, traceFlags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" {
+		return TraceContext{}, false
+	}
+	//Future versions may append fields, but version 00 must contain exactly four
+	if version == traceparentVersion && len(parts) != 4 {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(traceID, 32) || isZeroHex(traceID) {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(parentID, 16) || isZeroHex(parentID) {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(traceFlags, 2) {
+		return TraceContext{}, false
+	}
+
+	return TraceContext{TraceID: traceID, SpanID: parentID, TraceFlags: traceFlags}, true
+}
+
+//formatTraceparent generates a W3C traceparent header value
+func formatTraceparent(traceID string, parentID string, traceFlags string) string {
+	return fmt.Sprintf("%s-%s-%s-%s", traceparentVersion, traceID, parentID, traceFlags)
+}
+
+//traceFlagsSampled returns false only if the provided trace flags are valid and the sampled flag is not set
+func traceFlagsSampled(traceFlags string) bool {
+	flags, err := strconv.ParseUint(traceFlags, 16, 8)
+	if err != nil {
+		return true
+	}
+	return flags&1 == 1
+}
+
+//w3cTraceID converts a trace ID to the 32 character hex format required by W3C Trace Context
+//	UUIDs are converted by removing the dashes and 64-bit IDs are left padded with zeros. Any other IDs are hashed.
+func w3cTraceID(id string) string {
+	hexID := strings.ToLower(strings.ReplaceAll(id, "-", ""))
+	if isLowerHex(hexID, 16) {
+		hexID = strings.Repeat("0", 16) + hexID
+	}
+	if isLowerHex(hexID, 32) && !isZeroHex(hexID) {
+		return hexID
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
+
+//w3cSpanID converts a span ID to the 16 character hex format required by W3C Trace Context
+//	UUIDs are converted by using the first 16 characters with the dashes removed. Any other IDs are hashed.
+func w3cSpanID(id string) string {
+	hexID := strings.ToLower(strings.ReplaceAll(id, "-", ""))
+	if len(hexID) >= 16 && isLowerHex(hexID[:16], 16) && !isZeroHex(hexID[:16]) {
+		return hexID[:16]
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
+
+//isLowerHex returns true if value is a lowercase hex string of the provided length
+func isLowerHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+
+	for _, c := range value {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+//isZeroHex returns true if value contains only zeros
+func isZeroHex(value string) bool {
+	return strings.Trim(value, "0") == ""
+}
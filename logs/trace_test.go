@@ -0,0 +1,89 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  TraceContext
+		ok    bool
+	}{
+		{name: "valid sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want: TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", TraceFlags: "01"}, ok: true},
+		{name: "valid unsampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			want: TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", TraceFlags: "00"}, ok: true},
+		{name: "surrounding whitespace", value: " 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01 ",
+			want: TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", TraceFlags: "01"}, ok: true},
+		{name: "future version with extra field", value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-abc",
+			want: TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", TraceFlags: "01"}, ok: true},
+		{name: "uppercase trace id", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{name: "uppercase parent id", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00F067AA0BA902B7-01"},
+		{name: "uppercase version", value: "0A-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "zero trace id", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "zero parent id", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{name: "version ff", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "version 00 with extra field", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-abc"},
+		{name: "short trace id", value: "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01"},
+		{name: "long parent id", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7a-01"},
+		{name: "invalid flags", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x"},
+		{name: "non-hex version", value: "zz-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "missing flags", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"},
+		{name: "empty", value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTraceparent(tt.value)
+			if ok != tt.ok {
+				t.Fatalf("parseTraceparent(%q) ok = %v, want %v", tt.value, ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("parseTraceparent(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectEmptyTraceContext(t *testing.T) {
+	propagators := []Propagator{LegacyPropagator{}, W3CPropagator{}, B3Propagator{}, B3Propagator{SingleHeader: true}}
+	for _, propagator := range propagators {
+		t.Run(fmt.Sprintf("%T%+v", propagator, propagator), func(t *testing.T) {
+			header := http.Header{}
+			propagator.Inject(header, TraceContext{})
+			if len(header) != 0 {
+				t.Errorf("Inject() with an empty trace context wrote %v, want no headers", header)
+			}
+		})
+	}
+}
+
+func TestSetHeadersWithoutTraceContext(t *testing.T) {
+	logger := NewLogger("test", nil)
+
+	noop := newOutboundRequest()
+	FromContext(context.Background()).SetHeaders(noop)
+	if len(noop.Header) != 0 {
+		t.Errorf("SetHeaders() on a no-op Log wrote %v, want no headers", noop.Header)
+	}
+
+	first, second := newOutboundRequest(), newOutboundRequest()
+	logger.NewRequestLog(nil).SetHeaders(first)
+	logger.NewRequestLog(nil).SetHeaders(second)
+	if first.Header.Get(headerTraceparent) == "" {
+		t.Fatal("SetHeaders() on a Log without a request did not write traceparent")
+	}
+	if first.Header.Get(headerTraceparent) == second.Header.Get(headerTraceparent) {
+		t.Errorf("SetHeaders() wrote the same traceparent %q for unrelated Logs", first.Header.Get(headerTraceparent))
+	}
+}
+
+func newOutboundRequest() *http.Request {
+	r, _ := http.NewRequest("GET", "http://example.com", nil)
+	return r
+}
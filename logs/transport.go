@@ -0,0 +1,69 @@
+package logs
+
+import (
+	"net/http"
+)
+
+//Transport is an http.RoundTripper which performs logging and trace propagation for outbound requests
+//	The Log stored in the request context (see NewContext) is used to create a child span for each request, inject the
+//	trace headers, and print the method, host, path, status code and duration of the request.
+//	Requests without a Log in their context are sent without logging.
+type Transport struct {
+	//Base: The http.RoundTripper used to send requests. If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+//NewTransport creates a Transport which sends requests using base
+//	base: The http.RoundTripper used to send requests (nil for http.DefaultTransport)
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+//RoundTrip sends the request using the base http.RoundTripper and logs the result
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	log, ok := logFromContext(req.Context())
+	if !ok || log.logger == nil {
+		return base.RoundTrip(req)
+	}
+
+	span := log.StartSpan("HTTP " + req.Method + " " + req.URL.Host)
+	span.SetAttribute("method", req.Method)
+	span.SetAttribute("host", req.URL.Host)
+	span.SetAttribute("path", req.URL.Path)
+	if query := log.logger.redactQuery(req.URL.RawQuery); query != nil {
+		span.SetAttribute("query", query)
+	}
+
+	//A RoundTripper must not modify the provided request
+	outReq := req.Clone(req.Context())
+	span.SetHeaders(outReq)
+	headers, omittedHeaders := log.logger.redactHeaders(outReq.Header)
+	span.SetAttribute("headers", headers)
+	if omittedHeaders > 0 {
+		span.SetAttribute("omitted_headers", omittedHeaders)
+	}
+
+	resp, err := base.RoundTrip(outReq)
+	if err != nil {
+		span.SetStatus(SpanStatusError)
+		span.SetAttribute("error", err.Error())
+		span.LogError("Error sending outbound request", err)
+		span.End()
+		return resp, err
+	}
+
+	span.SetAttribute("status_code", resp.StatusCode)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(SpanStatusError)
+	} else {
+		span.SetStatus(SpanStatusOk)
+	}
+	span.End()
+
+	return resp, nil
+}